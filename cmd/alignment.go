@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+type ErrorCounts struct {
+	Substitutions  int `json:",omitempty"` // Wrong letter
+	Insertions     int `json:",omitempty"` // Extra letter
+	Omissions      int `json:",omitempty"` // Missed letter
+	Transpositions int `json:",omitempty"` // Two letters swapped
+}
+
+func (counts ErrorCounts) total() int {
+	return counts.Substitutions + counts.Insertions + counts.Omissions + counts.Transpositions
+}
+
+func (counts ErrorCounts) add(other ErrorCounts) ErrorCounts {
+	return ErrorCounts{
+		Substitutions:  counts.Substitutions + other.Substitutions,
+		Insertions:     counts.Insertions + other.Insertions,
+		Omissions:      counts.Omissions + other.Omissions,
+		Transpositions: counts.Transpositions + other.Transpositions,
+	}
+}
+
+func (counts ErrorCounts) String() string {
+	return fmt.Sprintf("%d wrong, %d extra, %d missed, %d swapped", counts.Substitutions, counts.Insertions, counts.Omissions, counts.Transpositions)
+}
+
+type alignedWord struct {
+	typed    string
+	expected string
+	counts   ErrorCounts
+}
+
+// Pairs typed words with expected ones, so a skipped letter costs one error instead of the rest of the word
+func (base TestBase) errorCounts() ErrorCounts {
+	var acc ErrorCounts
+
+	typedWords := strings.Split(string(base.typedRange(0, len(base.inputBuffer))), " ")
+	expectedWords := strings.Split(string(base.wordsToEnter), " ")
+	finished := len(base.inputBuffer) >= len(base.wordsToEnter)
+
+	for idx, typed := range typedWords {
+		var expected string
+		if idx < len(expectedWords) {
+			expected = expectedWords[idx]
+		}
+
+		// The word being typed when the test ended is not missing its unwritten letters
+		partial := idx == len(typedWords)-1 && !finished
+		if partial {
+			acc = acc.add(alignWord([]rune(typed), []rune(expected), partial))
+			continue
+		}
+
+		// The live speed display asks every tick, finished words are aligned once
+		cached, ok := base.aligned[idx]
+		if !ok || cached.typed != typed || cached.expected != expected {
+			cached = alignedWord{typed, expected, alignWord([]rune(typed), []rune(expected), false)}
+			if base.aligned != nil {
+				base.aligned[idx] = cached
+			}
+		}
+		acc = acc.add(cached.counts)
+	}
+
+	return acc
+}
+
+// What was actually typed over the given span of wordsToEnter: skipped letters are left out,
+// extra letters are put back in front of the space they were typed at
+func (base TestBase) typedRange(start int, end int) []rune {
+	var acc []rune
+	for at := start; at <= end; at++ {
+		acc = append(acc, base.overflow[at]...)
+		if at < end && at < len(base.inputBuffer) && !base.mistakes.omittedAt[at] {
+			acc = append(acc, base.inputBuffer[at])
+		}
+	}
+
+	return acc
+}
+
+// Mistakes in the input as it is, before alignment
+func (base TestBase) positionalMistakes() int {
+	acc := len(base.mistakes.mistakesAt)
+	for _, extra := range base.overflow {
+		acc += len(extra)
+	}
+
+	return acc
+}
+
+// Optimal string alignment distance, with the edits classified by backtracking through the table.
+// A partial word is compared against the best matching prefix of the expected one.
+func alignWord(typed []rune, expected []rune, partial bool) ErrorCounts {
+	rows, cols := len(typed)+1, len(expected)+1
+	distance := make([][]int, rows)
+	for i := range distance {
+		distance[i] = make([]int, cols)
+		distance[i][0] = i
+	}
+	for j := range distance[0] {
+		distance[0][j] = j
+	}
+
+	for i := 1; i < rows; i++ {
+		for j := 1; j < cols; j++ {
+			cost := 1
+			if typed[i-1] == expected[j-1] {
+				cost = 0
+			}
+			distance[i][j] = min(distance[i-1][j]+1, distance[i][j-1]+1, distance[i-1][j-1]+cost)
+			if isSwap(typed, expected, i, j) {
+				distance[i][j] = min(distance[i][j], distance[i-2][j-2]+1)
+			}
+		}
+	}
+
+	i, j := len(typed), len(expected)
+	if partial {
+		for at := range distance[i] {
+			if distance[i][at] < distance[i][j] {
+				j = at
+			}
+		}
+	}
+
+	var acc ErrorCounts
+	for i > 0 || j > 0 {
+		switch {
+		case isSwap(typed, expected, i, j) && distance[i][j] == distance[i-2][j-2]+1:
+			acc.Transpositions++
+			i, j = i-2, j-2
+		case i > 0 && j > 0 && typed[i-1] == expected[j-1] && distance[i][j] == distance[i-1][j-1]:
+			i, j = i-1, j-1
+		case i > 0 && j > 0 && distance[i][j] == distance[i-1][j-1]+1:
+			acc.Substitutions++
+			i, j = i-1, j-1
+		case i > 0 && distance[i][j] == distance[i-1][j]+1:
+			acc.Insertions++
+			i--
+		default:
+			acc.Omissions++
+			j--
+		}
+	}
+
+	return acc
+}
+
+func isSwap(typed []rune, expected []rune, i int, j int) bool {
+	return i > 1 && j > 1 &&
+		typed[i-1] == expected[j-2] && typed[i-2] == expected[j-1] && typed[i-1] != expected[j-1]
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import "testing"
+
+func TestAlignWord(t *testing.T) {
+	tests := []struct {
+		name     string
+		typed    string
+		expected string
+		partial  bool
+		want     ErrorCounts
+	}{
+		{name: "exact", typed: "the", expected: "the", want: ErrorCounts{}},
+		{name: "substitution", typed: "tha", expected: "the", want: ErrorCounts{Substitutions: 1}},
+		{name: "transposition", typed: "teh", expected: "the", want: ErrorCounts{Transpositions: 1}},
+		{name: "transposition at the start", typed: "hte", expected: "the", want: ErrorCounts{Transpositions: 1}},
+		{name: "omission", typed: "te", expected: "the", want: ErrorCounts{Omissions: 1}},
+		{name: "omission keeps the rest aligned", typed: "keyboad", expected: "keyboard", want: ErrorCounts{Omissions: 1}},
+		{name: "insertion", typed: "thee", expected: "the", want: ErrorCounts{Insertions: 1}},
+		{name: "insertion in the middle", typed: "thhe", expected: "the", want: ErrorCounts{Insertions: 1}},
+		{name: "skipped word", typed: "", expected: "the", want: ErrorCounts{Omissions: 3}},
+		{name: "extra word", typed: "the", expected: "", want: ErrorCounts{Insertions: 3}},
+		{name: "mixed", typed: "recieve", expected: "receive", want: ErrorCounts{Transpositions: 1}},
+		{name: "partial prefix", typed: "th", expected: "the", partial: true, want: ErrorCounts{}},
+		{name: "partial with a swap", typed: "tehr", expected: "there", partial: true, want: ErrorCounts{Transpositions: 1}},
+		{name: "unfinished word counts as omissions", typed: "th", expected: "the", want: ErrorCounts{Omissions: 1}},
+		{name: "multibyte swap", typed: "žsąis", expected: "žąsis", want: ErrorCounts{Transpositions: 1}},
+		{name: "multibyte omission", typed: "ąžuols", expected: "ąžuolas", want: ErrorCounts{Omissions: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := alignWord([]rune(tt.typed), []rune(tt.expected), tt.partial); got != tt.want {
+				t.Errorf("alignWord(%q, %q, %v) = %+v, want %+v", tt.typed, tt.expected, tt.partial, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorCounts(t *testing.T) {
+	tests := []struct {
+		name    string
+		toEnter string
+		inputs  []string // Buffer after each step, counted every time like the live display does
+		want    ErrorCounts
+	}{
+		{name: "finished test", toEnter: "the cat", inputs: []string{"teh cat"}, want: ErrorCounts{Transpositions: 1}},
+		{name: "last word unfinished", toEnter: "the cat", inputs: []string{"the ca"}, want: ErrorCounts{}},
+		{name: "cached word fixed later", toEnter: "the cat sat", inputs: []string{"teh ", "teh c", "the c", "the cat sat"}, want: ErrorCounts{}},
+		{name: "cached word broken later", toEnter: "the cat sat", inputs: []string{"the ", "the cat ", "thx cat sat"}, want: ErrorCounts{Substitutions: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := TestBase{
+				wordsToEnter: []rune(tt.toEnter),
+				mistakes:     mistakes{mistakesAt: map[int]bool{}, omittedAt: map[int]bool{}},
+				overflow:     map[int][]rune{},
+				aligned:      map[int]alignedWord{},
+			}
+
+			var got ErrorCounts
+			for _, input := range tt.inputs {
+				base.inputBuffer = []rune(input)
+				got = base.errorCounts()
+			}
+			if got != tt.want {
+				t.Errorf("errorCounts() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
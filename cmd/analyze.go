@@ -0,0 +1,238 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/bloznelis/typioca/cmd/words"
+)
+
+var fingerNames = []string{
+	"left pinky", "left ring", "left middle", "left index",
+	"right index", "right middle", "right ring", "right pinky",
+}
+
+// Keys reached by stretching the index finger sideways
+const centerColumns = "5tgb6yhn"
+
+type LayoutAnalysis struct {
+	Layout           string    `json:"layout"`
+	Letters          int       `json:"letters"`
+	Untypable        int       `json:"untypable"`         // Letters of the text the layout has no key for
+	FingerLoad       []float64 `json:"fingerLoad"`        // Percent of letters, from the left pinky to the right pinky
+	SameFinger       float64   `json:"sameFingerBigrams"` // The rest are percents of bigrams
+	LateralStretches float64   `json:"lateralStretches"`
+	Alternation      float64   `json:"alternation"`
+	InwardRolls      float64   `json:"inwardRolls"`  // Adjacent fingers of one hand on the same or a neighbouring row, towards the index finger
+	OutwardRolls     float64   `json:"outwardRolls"` // The same towards the pinky
+}
+
+// Bigrams are pairs of letters within a word, spaces and untypable letters break them
+func analyzeLayout(layout Layout, text []rune) LayoutAnalysis {
+	analysis := LayoutAnalysis{Layout: layout.displayName(), FingerLoad: make([]float64, len(fingerNames))}
+
+	var bigrams, sameFinger, stretches, alternation, inward, outward int
+	var previous keyPress
+	hasPrevious := false
+
+	for _, r := range text {
+		press, ok := layout.keyPressFor(r)
+		if !ok || press.physical == ' ' {
+			if !ok && r != ' ' {
+				analysis.Untypable++
+			}
+			hasPrevious = false
+			continue
+		}
+
+		finger := fingerOf(press.physical)
+		analysis.Letters++
+		analysis.FingerLoad[finger]++
+
+		if hasPrevious {
+			previousFinger := fingerOf(previous.physical)
+			bigrams++
+			switch {
+			case previous.physical == press.physical:
+			case finger == previousFinger:
+				sameFinger++
+			case (finger < 4) != (previousFinger < 4):
+				alternation++
+			default:
+				if isLateralStretch(previous.physical, press.physical) {
+					stretches++
+				}
+				if isRoll(previous.physical, press.physical) {
+					// Towards the index finger is towards the middle of the keyboard
+					if (finger < 4) == (finger > previousFinger) {
+						inward++
+					} else {
+						outward++
+					}
+				}
+			}
+		}
+		previous, hasPrevious = press, true
+	}
+
+	for finger := range analysis.FingerLoad {
+		analysis.FingerLoad[finger] = percent(int(analysis.FingerLoad[finger]), analysis.Letters)
+	}
+	analysis.SameFinger = percent(sameFinger, bigrams)
+	analysis.LateralStretches = percent(stretches, bigrams)
+	analysis.Alternation = percent(alternation, bigrams)
+	analysis.InwardRolls = percent(inward, bigrams)
+	analysis.OutwardRolls = percent(outward, bigrams)
+
+	return analysis
+}
+
+// Index finger on the center column next to the middle finger of the same hand
+func isLateralStretch(first rune, second rune) bool {
+	for _, pair := range [][2]rune{{first, second}, {second, first}} {
+		finger := fingerOf(pair[1])
+		if strings.ContainsRune(centerColumns, pair[0]) && (finger == 2 || finger == 5) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isRoll(first rune, second rune) bool {
+	fingerDistance := fingerOf(first) - fingerOf(second)
+	rowDistance := rowOf(first) - rowOf(second)
+
+	return (fingerDistance == 1 || fingerDistance == -1) && rowDistance <= 1 && rowDistance >= -1
+}
+
+func percent(part int, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	return float64(part) / float64(total) * 100
+}
+
+func (layout Layout) displayName() string {
+	if layout.Name == "" {
+		return "Qwerty"
+	}
+
+	return layout.Name
+}
+
+// What was meant to be typed in the saved tests, with a space wherever the cursor jumped
+func historyText(logs []keystrokeLog) []rune {
+	var acc []rune
+	for _, log := range logs {
+		cursor := -1
+		for _, event := range log {
+			if event.Kind != runeEvent && event.Kind != spaceEvent || event.Expected == 0 {
+				continue
+			}
+			if event.Cursor != cursor+1 {
+				acc = append(acc, ' ')
+			}
+			acc = append(acc, event.Expected)
+			cursor = event.Cursor
+		}
+		acc = append(acc, ' ')
+	}
+
+	return acc
+}
+
+// Layout from the config by name, or from a layout JSON file
+func findLayout(config Config, name string) (Layout, error) {
+	for _, layoutFile := range config.LayoutFiles {
+		if layoutFile.Name != name {
+			continue
+		}
+		if layoutFile.Path == "" && !layoutFile.isLocal {
+			return Layout{Name: layoutFile.Name}, nil
+		}
+		if layoutFile.problem != "" {
+			return Layout{}, fmt.Errorf("layout %q: %s", name, layoutFile.problem)
+		}
+		if !layoutFile.synced {
+			return Layout{}, fmt.Errorf("layout %q is not synced, sync it in the config view", name)
+		}
+
+		return retrieveLayout(layoutFile), nil
+	}
+
+	if !fileExists(name) {
+		return Layout{}, fmt.Errorf("no layout %q in the config and no such file", name)
+	}
+	layout, err := readLayoutFile(name)
+	if err != nil {
+		return Layout{}, err
+	}
+	if layout.Name == "" {
+		layout.Name = strings.TrimSuffix(filepath.Base(name), filepath.Ext(name))
+	}
+
+	return layout, nil
+}
+
+// Words of a list from the config by name, or of a word list file
+func findWords(config Config, name string) ([]rune, error) {
+	path := name
+	for _, list := range config.WordLists {
+		if list.Name == name {
+			if !list.synced {
+				return nil, fmt.Errorf("word list %q is not synced", name)
+			}
+			path = list.Path
+		}
+	}
+
+	generator := words.NewGenerator(nil)
+	if _, ok := generator.Words(name); !ok {
+		if !fileExists(path) {
+			return nil, fmt.Errorf("no word list %q in the config and no such file", name)
+		}
+		generator = words.NewGenerator([]string{path})
+		name = path
+	}
+
+	list, ok := generator.Words(name)
+	if !ok {
+		return nil, fmt.Errorf("word list %q could not be read", name)
+	}
+
+	return []rune(strings.Join(list, " ")), nil
+}
+
+// One line per metric, one column per layout
+func analysisTable(analyses []LayoutAnalysis) []string {
+	row := func(label string, value func(analysis LayoutAnalysis) string) string {
+		line := fmt.Sprintf("%-18s", label)
+		for _, analysis := range analyses {
+			line += fmt.Sprintf(" %14s", value(analysis))
+		}
+		return line
+	}
+	percentRow := func(label string, value func(analysis LayoutAnalysis) float64) string {
+		return row(label, func(analysis LayoutAnalysis) string { return fmt.Sprintf("%.1f%%", value(analysis)) })
+	}
+
+	lines := []string{
+		row("", func(analysis LayoutAnalysis) string { return analysis.Layout }),
+		row("letters", func(analysis LayoutAnalysis) string { return fmt.Sprint(analysis.Letters) }),
+		row("untypable", func(analysis LayoutAnalysis) string { return fmt.Sprint(analysis.Untypable) }),
+	}
+	for finger, name := range fingerNames {
+		lines = append(lines, percentRow(name, func(analysis LayoutAnalysis) float64 { return analysis.FingerLoad[finger] }))
+	}
+
+	return append(lines,
+		percentRow("same finger", func(analysis LayoutAnalysis) float64 { return analysis.SameFinger }),
+		percentRow("lateral stretches", func(analysis LayoutAnalysis) float64 { return analysis.LateralStretches }),
+		percentRow("alternation", func(analysis LayoutAnalysis) float64 { return analysis.Alternation }),
+		percentRow("inward rolls", func(analysis LayoutAnalysis) float64 { return analysis.InwardRolls }),
+		percentRow("outward rolls", func(analysis LayoutAnalysis) float64 { return analysis.OutwardRolls }),
+	)
+}
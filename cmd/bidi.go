@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"strings"
+
+	"golang.org/x/text/unicode/bidi"
+)
+
+var mirroredGlyphs = map[string]string{
+	"(": ")", ")": "(",
+	"[": "]", "]": "[",
+	"{": "}", "}": "{",
+	"<": ">", ">": "<",
+	"«": "»", "»": "«",
+}
+
+// Most terminals don't do bidi themselves, so right-to-left lines are put into visual order here.
+// It is a simplified take on the Unicode bidi algorithm: right-to-left paragraph,
+// with left-to-right words and numbers embedded in it.
+func reorderRightToLeft(line []glyph) []glyph {
+	embedded := make([]bool, len(line))
+	classes := make([]bidi.Class, len(line))
+	for idx, g := range line {
+		props, _ := bidi.LookupString(g.text)
+		classes[idx] = props.Class()
+	}
+
+	for idx, class := range classes {
+		switch class {
+		case bidi.L, bidi.EN, bidi.AN:
+			embedded[idx] = true
+		case bidi.ES, bidi.CS:
+			// Separators within a number, e.g. 3.14
+			embedded[idx] = isNumberAt(classes, idx-1) && isNumberAt(classes, idx+1)
+		case bidi.ET:
+			// Terminators next to a number, e.g. 10%
+			embedded[idx] = isNumberAt(classes, idx-1) || isNumberAt(classes, idx+1)
+		}
+	}
+
+	// Neutrals join left-to-right text only when it is on both sides of them
+	for idx, class := range classes {
+		if embedded[idx] || class == bidi.R || class == bidi.AL {
+			continue
+		}
+		embedded[idx] = isLeftToRightAround(classes, idx)
+	}
+
+	visual := make([]glyph, 0, len(line))
+	for end := len(line); end > 0; {
+		start := end - 1
+		if embedded[start] {
+			for start > 0 && embedded[start-1] {
+				start--
+			}
+			visual = append(visual, line[start:end]...)
+		} else {
+			g := line[start]
+			if mirrored, ok := mirroredGlyphs[g.text]; ok {
+				g.text = mirrored
+			}
+			visual = append(visual, g)
+		}
+		end = start
+	}
+
+	return visual
+}
+
+func isNumberAt(classes []bidi.Class, idx int) bool {
+	return idx >= 0 && idx < len(classes) && (classes[idx] == bidi.EN || classes[idx] == bidi.AN)
+}
+
+func isLeftToRightAround(classes []bidi.Class, idx int) bool {
+	return strongClassFrom(classes, idx, -1) == bidi.L && strongClassFrom(classes, idx, 1) == bidi.L
+}
+
+// First strong class when walking from idx in the given direction. Numbers count as right-to-left here.
+func strongClassFrom(classes []bidi.Class, idx int, step int) bidi.Class {
+	for at := idx + step; at >= 0 && at < len(classes); at += step {
+		switch classes[at] {
+		case bidi.L:
+			return bidi.L
+		case bidi.R, bidi.AL, bidi.EN, bidi.AN:
+			return bidi.R
+		}
+	}
+
+	return bidi.R
+}
+
+func alignRight(lines []string) []string {
+	widest := 0
+	for _, line := range lines {
+		widest = max(widest, stringWidth(line))
+	}
+
+	aligned := make([]string, len(lines))
+	for idx, line := range lines {
+		aligned[idx] = strings.Repeat(" ", widest-stringWidth(line)) + line
+	}
+
+	return aligned
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"sort"
+	"time"
+	"unicode"
+)
+
+// Fewer timed transitions than this say more about luck than about the transition
+const minBigramSamples = 3
+
+type BigramStat struct {
+	Bigram    string  `json:"bigram"`
+	Count     int     `json:"count"`
+	Mistakes  int     `json:"mistakes"`
+	AverageMs float64 `json:"averageMs"` // Between the two letters, over correctly typed transitions only
+	timed     int
+	total     time.Duration
+}
+
+func (stat BigramStat) errorRate() float64 {
+	return float64(stat.Mistakes) / float64(stat.Count)
+}
+
+// Transitions between consecutive letters of the text. Corrections break the chain,
+// so the letter typed after a backspace is not paired with the deleted one.
+func bigramStats(logs []keystrokeLog) []BigramStat {
+	stats := map[string]*BigramStat{}
+
+	for _, log := range logs {
+		var previous *InputEvent
+		for idx := range log {
+			event := &log[idx]
+			switch event.Kind {
+			case pendingEvent:
+				continue
+			case backspaceEvent, deleteWordEvent:
+				previous = nil
+				continue
+			}
+			if event.Expected == 0 {
+				previous = nil
+				continue
+			}
+
+			if previous != nil && previous.Cursor+1 == event.Cursor && previous.Mapped == previous.Expected {
+				bigram := string([]rune{unicode.ToLower(previous.Expected), unicode.ToLower(event.Expected)})
+				stat := stats[bigram]
+				if stat == nil {
+					stat = &BigramStat{Bigram: bigram}
+					stats[bigram] = stat
+				}
+
+				stat.Count++
+				latency := event.At - previous.At
+				if event.Mapped != event.Expected {
+					stat.Mistakes++
+				} else if latency < maxKeyLatency {
+					stat.total += latency
+					stat.timed++
+				}
+			}
+			previous = event
+		}
+	}
+
+	var acc []BigramStat
+	for _, stat := range stats {
+		if stat.timed > 0 {
+			stat.AverageMs = float64(stat.total.Microseconds()) / 1000 / float64(stat.timed)
+		}
+		acc = append(acc, *stat)
+	}
+
+	return acc
+}
+
+func slowestBigrams(stats []BigramStat) []BigramStat {
+	var sorted []BigramStat
+	for _, stat := range stats {
+		if stat.timed >= minBigramSamples {
+			sorted = append(sorted, stat)
+		}
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].AverageMs != sorted[j].AverageMs {
+			return sorted[i].AverageMs > sorted[j].AverageMs
+		}
+		return sorted[i].Bigram < sorted[j].Bigram
+	})
+
+	return sorted
+}
+
+func errorProneBigrams(stats []BigramStat) []BigramStat {
+	var sorted []BigramStat
+	for _, stat := range stats {
+		if stat.Mistakes > 0 {
+			sorted = append(sorted, stat)
+		}
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].errorRate() != sorted[j].errorRate() {
+			return sorted[i].errorRate() > sorted[j].errorRate()
+		}
+		if sorted[i].Count != sorted[j].Count {
+			return sorted[i].Count > sorted[j].Count
+		}
+		return sorted[i].Bigram < sorted[j].Bigram
+	})
+
+	return sorted
+}
+
+// Bigram as it is shown, with the space made visible
+func showBigram(bigram string) string {
+	var acc []rune
+	for _, letter := range bigram {
+		if letter == ' ' {
+			letter = '␣'
+		}
+		acc = append(acc, letter)
+	}
+
+	return string(acc)
+}
@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const defaultCalibratedName = "native"
+
+// Builds a layout out of what the system layout types on each key
+type CalibrationWizard struct {
+	step       int
+	captured   map[rune]rune // What was typed for each Qwerty key, shifted ones included
+	naming     bool
+	name       []rune
+	saved      string // Where the layout was written, empty until then
+	err        error
+	configView ConfigView
+}
+
+func initCalibrationWizard(configView ConfigView) CalibrationWizard {
+	return CalibrationWizard{
+		captured:   map[rune]rune{},
+		configView: configView,
+	}
+}
+
+func (wizard CalibrationWizard) handleInput(msg tea.Msg, state State) State {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return state
+	}
+
+	action := wizard.configView.config.keymap.action(wizardContext, key)
+	switch {
+	case wizard.saved != "":
+		if action == selectAction || action == menuAction {
+			// The saved layout shows up once the config is read again
+			configView := initConfigView(ReadConfig(), wizard.configView.mainMenu)
+			configView.cursor = wizard.configView.cursor
+			return configView
+		}
+	case action == menuAction:
+		return wizard.configView
+	case wizard.naming:
+		// A failed save keeps the captured keys, another name can be tried
+		switch {
+		case action == selectAction:
+			wizard.saved, wizard.err = wizard.save()
+		case action == deleteLetterAction:
+			wizard.err = nil
+			if len(wizard.name) > 0 {
+				wizard.name = wizard.name[:len(wizard.name)-1]
+			}
+		case key.Type == tea.KeyRunes && !key.Paste, key.Type == tea.KeySpace:
+			wizard.err = nil
+			wizard.name = append(wizard.name, key.Runes...)
+		}
+	case action == selectAction:
+		wizard.naming = true
+	case action == skipAction:
+		wizard = wizard.next()
+	case action == deleteLetterAction:
+		if wizard.step > 0 {
+			wizard.step--
+			delete(wizard.captured, qwertyKeys[wizard.step])
+		}
+	case key.Type == tea.KeyRunes && !key.Paste:
+		wizard.captured[qwertyKeys[wizard.step]] = key.Runes[0]
+		wizard = wizard.next()
+	}
+
+	return wizard
+}
+
+func (wizard CalibrationWizard) next() CalibrationWizard {
+	wizard.step++
+	wizard.naming = wizard.step == len(qwertyKeys)
+
+	return wizard
+}
+
+func (wizard CalibrationWizard) shiftPass() bool {
+	return wizard.step >= len(qwertyKeys)/2
+}
+
+func (wizard CalibrationWizard) layout() Layout {
+	name := strings.TrimSpace(string(wizard.name))
+	if name == "" {
+		name = defaultCalibratedName
+	}
+
+	layout := Layout{Name: name, Mappings: map[rune]rune{}}
+	for key, typed := range wizard.captured {
+		if key != typed {
+			layout.Mappings[key] = typed
+		}
+	}
+
+	return layout
+}
+
+func (wizard CalibrationWizard) save() (string, error) {
+	layout := wizard.layout()
+	if err := checkLayoutName(wizard.configView.config, layout.Name); err != nil {
+		return "", err
+	}
+
+	return saveLocalLayout(layout)
+}
+
+func (m model) calibrationView(wizard CalibrationWizard) string {
+	var view string
+
+	switch {
+	case wizard.saved != "":
+		view = fmt.Sprintf("Saved %s to %s\n\n", style(wizard.layout().Name, m.styles.runningTimer), wizard.saved) +
+			"It is in the layouts of the config view,\nswitch the system back to Qwerty before selecting it.\n\n" +
+			style(fmt.Sprintf("%s back to config", m.keymap.key(selectAction)), m.styles.toEnter)
+	case wizard.naming:
+		problem := ""
+		if wizard.err != nil {
+			problem = style(wizard.err.Error(), m.styles.mistakes) + "\n\n"
+		}
+		view = fmt.Sprintf("Calibrated %d keys\n\n%sname: %s%s\n\n", len(wizard.captured), problem, string(wizard.name), style(" ", m.styles.cursor)) +
+			style(fmt.Sprintf("empty for %q, %s save, %s to config", defaultCalibratedName, m.keymap.key(selectAction), m.keymap.key(menuAction)), m.styles.toEnter)
+	default:
+		instruction := "Switch the system to the layout to capture, then press the highlighted key"
+		if wizard.shiftPass() {
+			instruction = "Now hold shift and press the highlighted key"
+		}
+
+		current := qwertyKeys[wizard.step]
+		keyboard := renderKeyboard(Layout{}, staggeredKeyboard, 4, func(physical rune, _ rune) string {
+			key := physical
+			if wizard.shiftPass() {
+				key = qwertyShifted(physical)
+			}
+
+			if key == current {
+				return style(fmt.Sprintf(" %c ", key), m.styles.nextKey[fingerStyle(fingerOf(physical))])
+			}
+			if typed, ok := wizard.captured[key]; ok {
+				return style(fmt.Sprintf(" %c ", typed), m.styles.correct)
+			}
+			return style(fmt.Sprintf(" %c ", key), m.styles.toEnter)
+		})
+
+		view = fmt.Sprintf("%s\n\n%d/%d\n\n%s\n\n", instruction, wizard.step+1, len(qwertyKeys), keyboard) +
+			style(fmt.Sprintf("%s skip key, %s previous key, %s finish, %s to config", m.keymap.key(skipAction), m.keymap.key(deleteLetterAction), m.keymap.key(selectAction), m.keymap.key(menuAction)), m.styles.toEnter)
+	}
+
+	view = lipgloss.NewStyle().Align(lipgloss.Center).Render("Calibrate layout\n\n" + view)
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, view)
+}
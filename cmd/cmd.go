@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
@@ -20,10 +21,20 @@ import (
 )
 
 var (
-	serverBind    = ""
-	serverPort    = 2229
-	serverKeyPath = ""
-	showVersion   = false
+	serverBind     = ""
+	serverPort     = 2229
+	serverKeyPath  = ""
+	showVersion    = false
+	statsLimit     = 20
+	statsJson      = false
+	statsSort      = "slow"
+	layoutFormat   = ""
+	layoutName     = ""
+	layoutVariant  = ""
+	layoutOutput   = ""
+	analyzeLayouts []string
+	analyzeWords   = ""
+	analyzeJson    = false
 )
 
 var (
@@ -36,6 +47,10 @@ var (
 				fmt.Println("typioca ", Version)
 				return nil
 			} else {
+				if err := checkKeymap(); err != nil {
+					cmd.SilenceUsage = true
+					return err
+				}
 				termenv.SetWindowTitle("typioca")
 				defer println("bye!")
 
@@ -48,6 +63,7 @@ var (
 						termHeight,
 					),
 					tea.WithAltScreen(),
+					tea.WithReportFocus(),
 				)
 
 				return p.Start()
@@ -59,6 +75,11 @@ var (
 		Short: "Serve the typioca server",
 		Long:  "serve starts the typioca SSH server.",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkKeymap(); err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+
 			s, err := wish.NewServer(
 				wish.WithAddress(fmt.Sprintf("%s:%d", serverBind, serverPort)),
 				wish.WithHostKeyPath(serverKeyPath),
@@ -79,7 +100,7 @@ var (
 									pty.Window.Width,
 									pty.Window.Height,
 								),
-								[]tea.ProgramOption{tea.WithAltScreen()}
+								[]tea.ProgramOption{tea.WithAltScreen(), tea.WithReportFocus()}
 						}),
 				),
 			)
@@ -107,6 +128,137 @@ var (
 				return err
 			}
 
+			return nil
+		},
+	}
+	statsCmd = &cobra.Command{
+		Use:   "stats",
+		Short: "Show typing statistics",
+		Long:  "stats analyses the keystroke logs saved with the results.",
+	}
+	bigramsCmd = &cobra.Command{
+		Use:   "bigrams",
+		Short: "Show the slowest or most error-prone bigrams",
+		Long:  "bigrams shows the average time between two consecutive letters, over all saved results.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var bigrams []BigramStat
+			switch statsSort {
+			case "slow":
+				bigrams = slowestBigrams(bigramStats(LoadResults().keystrokeLogs()))
+			case "errors":
+				bigrams = errorProneBigrams(bigramStats(LoadResults().keystrokeLogs()))
+			default:
+				return fmt.Errorf("unknown sort %q, expected slow or errors", statsSort)
+			}
+
+			if statsLimit > 0 && len(bigrams) > statsLimit {
+				bigrams = bigrams[:statsLimit]
+			}
+
+			if statsJson {
+				if bigrams == nil {
+					bigrams = []BigramStat{}
+				}
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(bigrams)
+			}
+
+			fmt.Printf("%-8s %8s %8s %8s\n", "bigram", "avg ms", "count", "errors")
+			for _, stat := range bigrams {
+				fmt.Printf("%-8s %8.0f %8d %7.1f%%\n", showBigram(stat.Bigram), stat.AverageMs, stat.Count, stat.errorRate()*100)
+			}
+
+			return nil
+		},
+	}
+	layoutCmd = &cobra.Command{
+		Use:   "layout",
+		Short: "Work with keyboard layouts",
+		Long:  "layout imports keyboard layout definitions into typioca layouts and analyzes them.",
+	}
+	importLayoutCmd = &cobra.Command{
+		Use:   "import <file>",
+		Short: "Convert an XKB, KLC, QMK or kmonad layout",
+		Long:  "import converts a Linux XKB symbols file, a Microsoft KLC file, a 60% ANSI QMK keymap or a kmonad config into typioca's layout JSON. Keys that could not be converted are reported on stderr.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			data, err := os.ReadFile(args[0])
+			if err != nil {
+				return err
+			}
+
+			format := layoutFormat
+			if format == "" {
+				if format, err = detectLayoutFormat(args[0], data); err != nil {
+					return err
+				}
+			}
+
+			imported, err := importLayout(args[0], data, format, layoutName, layoutVariant)
+			if err != nil {
+				return err
+			}
+			for _, problem := range imported.problems {
+				fmt.Fprintln(os.Stderr, "warning:", problem)
+			}
+
+			encoded, err := json.Marshal(imported.layout)
+			if err != nil {
+				return err
+			}
+			if layoutOutput == "" {
+				fmt.Println(string(encoded))
+				return nil
+			}
+
+			return os.WriteFile(layoutOutput, append(encoded, '\n'), 0644)
+		},
+	}
+	analyzeLayoutCmd = &cobra.Command{
+		Use:   "analyze",
+		Short: "Compare finger usage of layouts",
+		Long:  "analyze computes finger load, same-finger bigrams, lateral stretches, alternation and inward/outward rolls of layouts, over a word list or the typed history.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config := ReadConfig()
+
+			var text []rune
+			if analyzeWords == "" {
+				text = historyText(LoadResults().keystrokeLogs())
+			} else {
+				var err error
+				if text, err = findWords(config, analyzeWords); err != nil {
+					return err
+				}
+			}
+
+			names := analyzeLayouts
+			if len(names) == 0 {
+				names = []string{config.Layout.displayName()}
+			}
+
+			var analyses []LayoutAnalysis
+			for _, name := range names {
+				layout, err := findLayout(config, name)
+				if err != nil {
+					return err
+				}
+				analyses = append(analyses, analyzeLayout(layout, text))
+			}
+
+			if analyzeJson {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(analyses)
+			}
+
+			if analyses[0].Letters == 0 {
+				return fmt.Errorf("nothing to analyze, finish a few tests first or pass --words")
+			}
+			for _, line := range analysisTable(analyses) {
+				fmt.Println(line)
+			}
+
 			return nil
 		},
 	}
@@ -117,5 +269,20 @@ func init() {
 	serveCmd.Flags().StringVarP(&serverBind, "bind", "b", "", "address to bind on")
 	serveCmd.Flags().IntVarP(&serverPort, "port", "p", 2229, "port to serve on")
 	RootCmd.Flags().BoolVarP(&showVersion, "version", "v", false, "show typioca version")
+	bigramsCmd.Flags().IntVarP(&statsLimit, "limit", "n", 20, "how many bigrams to show, 0 for all")
+	bigramsCmd.Flags().BoolVar(&statsJson, "json", false, "print as JSON")
+	bigramsCmd.Flags().StringVarP(&statsSort, "sort", "s", "slow", "slow or errors")
+	statsCmd.AddCommand(bigramsCmd)
+	importLayoutCmd.Flags().StringVarP(&layoutFormat, "format", "f", "", "xkb, klc, qmk or kmonad, guessed from the file when empty")
+	importLayoutCmd.Flags().StringVar(&layoutName, "name", "", "layout name, taken from the file when empty")
+	importLayoutCmd.Flags().StringVar(&layoutVariant, "variant", "", "XKB variant, the first one when empty")
+	importLayoutCmd.Flags().StringVarP(&layoutOutput, "output", "o", "", "file to write, stdout when empty")
+	analyzeLayoutCmd.Flags().StringSliceVarP(&analyzeLayouts, "layout", "l", nil, "layouts to compare, by name or JSON file, the current one when empty")
+	analyzeLayoutCmd.Flags().StringVarP(&analyzeWords, "words", "w", "", "word list by name or file, the typed history when empty")
+	analyzeLayoutCmd.Flags().BoolVar(&analyzeJson, "json", false, "print as JSON")
+	layoutCmd.AddCommand(importLayoutCmd)
+	layoutCmd.AddCommand(analyzeLayoutCmd)
 	RootCmd.AddCommand(serveCmd)
+	RootCmd.AddCommand(statsCmd)
+	RootCmd.AddCommand(layoutCmd)
 }
@@ -6,13 +6,16 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
 
 	"github.com/BurntSushi/toml"
 	"github.com/bloznelis/typioca/cmd/words"
 	"github.com/kirsle/configdir"
 )
 
-const currentConfigVersion = 4
+const currentConfigVersion = 6
 
 func ReadConfig() Config {
 	var config Config
@@ -29,6 +32,7 @@ func ReadConfig() Config {
 			WriteConfig(config)
 		}
 	}
+	config = applyCatalog(config, words.LoadCatalog(getCachePath()))
 	config = mergeConfigs(config)
 	checkSync(&config)
 
@@ -37,30 +41,79 @@ func ReadConfig() Config {
 
 func mergeConfigs(config Config) Config {
 	localConfigFile := getLocalConfigPath()
+	var localConfig LocalConfig
 
 	if _, err := os.Stat(localConfigFile); os.IsNotExist(err) {
 	} else {
-		var localConfig LocalConfig
 		readLocalConfigFile(&localConfig, localConfigFile)
 
 		config.WordLists = append(localConfig.Words, config.WordLists...)
 	}
+	for _, local := range append(localConfig.Layouts, discoverLocalLayouts(localConfig.Layouts)...) {
+		config.LayoutFiles = append(config.LayoutFiles, localLayoutFile(local))
+	}
+
+	config.languages = make(map[string]words.Language)
+	for _, language := range words.Languages {
+		config.languages[language.Code] = language
+	}
+	for _, override := range localConfig.Languages {
+		language, _ := words.LookupLanguage(override.Code)
+		if known, ok := config.languages[override.Code]; ok {
+			language = known
+		}
+		config.languages[override.Code] = language.Override(override)
+	}
+
+	// Checked by checkKeymap before starting, a file edited since then falls back to the defaults
+	keymap, err := newKeymap(localConfig.Keymap)
+	if err != nil {
+		keymap, _ = newKeymap(KeymapConfig{})
+	}
+	config.keymap = keymap
+
+	// Keep lists of the same language together
+	sort.SliceStable(config.WordLists, func(i, j int) bool {
+		return config.languageLess(config.WordLists[i].Language, config.WordLists[j].Language)
+	})
 
 	return config
 }
 
+// A file that does not match the catalog checksum is broken or outdated, it is shown as failed until synced again
 func checkSync(config *Config) {
 	for idx, elem := range config.WordLists {
-		config.WordLists[idx].synced = fileExists(elem.Path)
-		config.WordLists[idx].syncOK = true
+		config.WordLists[idx].synced, config.WordLists[idx].syncOK = checkFile(elem.Path, elem.Checksum)
 	}
 
 	for idx, elem := range config.LayoutFiles {
-		config.LayoutFiles[idx].synced = fileExists(elem.Path)
-		config.LayoutFiles[idx].syncOk = true
+		if elem.isLocal {
+			// A missing local file is shown as failed, there is nothing to download
+			config.LayoutFiles[idx].synced = elem.local != nil
+			config.LayoutFiles[idx].syncOk = elem.local != nil
+			continue
+		}
+		config.LayoutFiles[idx].synced, config.LayoutFiles[idx].syncOk = checkFile(elem.Path, elem.Checksum)
 	}
 }
 
+// Whether the file is synced and whether it is intact
+func checkFile(path string, checksum string) (bool, bool) {
+	if !fileExists(path) {
+		return false, true
+	}
+	if checksum == "" {
+		return true, true
+	}
+
+	sum, err := words.FileChecksum(path)
+	if err != nil || !strings.EqualFold(sum, checksum) {
+		return false, false
+	}
+
+	return true, true
+}
+
 func WriteConfig(config Config) {
 	configFile := getSystemConfigPath()
 	words.EnsureDir(configFile)
@@ -78,6 +131,14 @@ func WriteConfig(config Config) {
 	}
 	config.WordLists = acc
 
+	var layouts []LayoutFile
+	for _, elem := range config.LayoutFiles {
+		if !elem.isLocal {
+			layouts = append(layouts, elem)
+		}
+	}
+	config.LayoutFiles = layouts
+
 	encoder := json.NewEncoder(fh)
 	encoder.SetIndent("", "\t")
 	encoder.Encode(&config)
@@ -124,6 +185,18 @@ func readConfigFile(config *Config, configFile string) {
 	decoder.Decode(&config)
 }
 
+func checkKeymap() error {
+	var localConfig LocalConfig
+	if localConfigFile := getLocalConfigPath(); fileExists(localConfigFile) {
+		readLocalConfigFile(&localConfig, localConfigFile)
+	}
+	if _, err := newKeymap(localConfig.Keymap); err != nil {
+		return fmt.Errorf("%s: %w", getLocalConfigPath(), err)
+	}
+
+	return nil
+}
+
 func readLocalConfigFile(config *LocalConfig, configFile string) {
 	fh, err := os.Open(configFile)
 	if err != nil {
@@ -171,119 +244,294 @@ func githubLayoutsURI(fileName string) string {
 }
 
 func retrieveLayout(layout LayoutFile) Layout {
+	if layout.local != nil {
+		return *layout.local
+	}
+
 	if layout.Path == "" {
 		return Layout{
 			Name: layout.Name,
 		}
 	}
 
-	f, err := os.Open(layout.Path)
+	res, err := readLayoutFile(layout.Path)
 	if err != nil {
 		log.Println(layout.Path)
 		panic(err)
 	}
 
+	return res
+}
+
+func readLayoutFile(path string) (Layout, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Layout{}, err
+	}
+	defer f.Close()
+
 	var res Layout
 	dec := json.NewDecoder(f)
 	if err := dec.Decode(&res); err != nil {
-		panic(err)
+		return Layout{}, fmt.Errorf("%s is not a layout file: %w", path, err)
 	}
 
-	return res
+	return res, nil
 }
 
-func defaultLayoutFile(cachePath string, name string, localName string) LayoutFile {
-	if localName == "" {
-		return LayoutFile{
-			Name: name,
+// Missing or malformed local layouts are shown as failed in the config view
+func localLayoutFile(local LocalLayout) LayoutFile {
+	layoutFile := LayoutFile{Name: local.Name, Path: local.Path, isLocal: true}
+
+	var layout Layout
+	var err error
+	if local.Path != "" {
+		if !fileExists(local.Path) {
+			layoutFile.problem = "no such file"
+			return layoutFile
+		}
+		layout, err = readLayoutFile(local.Path)
+	} else {
+		layout = Layout{Sequences: local.Sequences}
+		if layout.Mappings, err = inlineMappings(local.Mappings); err == nil {
+			layout.AltMappings, err = inlineMappings(local.AltMappings)
 		}
 	}
+	if err != nil {
+		layoutFile.problem = err.Error()
+		return layoutFile
+	}
+
+	layout.Name = local.Name
+	layoutFile.local = &layout
+
+	return layoutFile
+}
+
+func localLayoutsPath() string {
+	return filepath.Join(configdir.LocalConfig("typioca"), "layouts")
+}
 
-	path := filepath.Join(cachePath, "layouts", localName)
+// Layout files saved to the local layouts dir, unless the TOML config already declares a layout of the same name
+func discoverLocalLayouts(declared []LocalLayout) []LocalLayout {
+	paths, _ := filepath.Glob(filepath.Join(localLayoutsPath(), "*.json"))
+
+	taken := map[string]bool{}
+	for _, local := range declared {
+		taken[local.Name] = true
+	}
+
+	var acc []LocalLayout
+	var failed []string
+	for _, path := range paths {
+		layout, err := readLayoutFile(path)
+		if err != nil || layout.Name == "" {
+			failed = append(failed, path)
+			continue
+		}
+		if !taken[layout.Name] {
+			taken[layout.Name] = true
+			acc = append(acc, LocalLayout{Name: layout.Name, Path: path})
+		}
+	}
+
+	// Unreadable files are still listed, as failed ones. They go by their file name, extension included,
+	// so they can't take the name of a working layout.
+	for _, path := range failed {
+		if name := filepath.Base(path); !taken[name] {
+			taken[name] = true
+			acc = append(acc, LocalLayout{Name: name, Path: path})
+		}
+	}
+
+	return acc
+}
+
+// Saved layouts may replace the ones in the local layouts dir, but not bundled or declared ones
+func checkLayoutName(config Config, name string) error {
+	for _, layoutFile := range config.LayoutFiles {
+		switch {
+		case layoutFile.Name != name:
+		case !layoutFile.isLocal:
+			return fmt.Errorf("%q is the name of a bundled layout", name)
+		case filepath.Dir(layoutFile.Path) != localLayoutsPath():
+			return fmt.Errorf("%q is declared in %s, edit it there", name, getLocalConfigPath())
+		}
+	}
+
+	return nil
+}
+
+func saveLocalLayout(layout Layout) (string, error) {
+	path := localLayoutPath(layout.Name)
+	if err := words.EnsureDir(path); err != nil {
+		return "", err
+	}
+	encoded, err := json.Marshal(layout)
+	if err != nil {
+		return "", err
+	}
+
+	return path, os.WriteFile(path, encoded, 0644)
+}
+
+// The file already holding a layout of that name, or a free one named after it.
+// Names differing only in case or punctuation get a number, so one can't overwrite the other.
+func localLayoutPath(name string) string {
+	dir := localLayoutsPath()
+	paths, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	for _, path := range paths {
+		if layout, err := readLayoutFile(path); err == nil && layout.Name == name {
+			return path
+		}
+	}
+
+	stem := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' {
+			return unicode.ToLower(r)
+		}
+		return '-'
+	}, name)
+	path := filepath.Join(dir, stem+".json")
+	for n := 2; fileExists(path); n++ {
+		path = filepath.Join(dir, fmt.Sprintf("%s-%d.json", stem, n))
+	}
+
+	return path
+}
+
+func inlineMappings(mappings map[string]string) (map[rune]rune, error) {
+	if mappings == nil {
+		return nil, nil
+	}
+
+	acc := make(map[rune]rune)
+	for key, value := range mappings {
+		from, to := []rune(key), []rune(value)
+		if len(from) != 1 || len(to) != 1 {
+			return nil, fmt.Errorf("mapping %q = %q must be a single character on both sides", key, value)
+		}
+		acc[from[0]] = to[0]
+	}
+
+	return acc, nil
+}
+
+// Catalog files are plain names inside the cache, anything else could write outside of it
+func checkCatalogFile(file string) error {
+	if file == "" || file == "." || file == ".." || filepath.Base(file) != file {
+		return fmt.Errorf("catalog file %q is not a plain file name", file)
+	}
+
+	return nil
+}
+
+func catalogLayoutFile(cachePath string, entry words.CatalogLayout) (LayoutFile, error) {
+	if err := checkCatalogFile(entry.File); err != nil {
+		return LayoutFile{}, err
+	}
+	path := filepath.Join(cachePath, "layouts", entry.File)
 
 	return LayoutFile{
-		Name:      name,
+		Name:      entry.Name,
 		Path:      path,
-		RemoteURI: githubLayoutsURI(localName),
+		RemoteURI: githubLayoutsURI(entry.File),
+		Checksum:  entry.Sha256,
 		synced:    fileExists(path),
-	}
+	}, nil
 }
 
-func defaultWordList(cachePath string, name string, localName string, enabled bool, sentences bool) WordList {
+func catalogWordList(cachePath string, entry words.CatalogWordList) (WordList, error) {
+	if err := checkCatalogFile(entry.File); err != nil {
+		return WordList{}, err
+	}
+
 	var subdir string
 	var uri string
-	if sentences {
+	if entry.Sentences {
 		subdir = "sentences"
-		uri = githubSentencesURI(localName)
+		uri = githubSentencesURI(entry.File)
 	} else {
 		subdir = "words"
-		uri = githubWordsURI(localName)
+		uri = githubWordsURI(entry.File)
 	}
 
-	file := filepath.Join(cachePath, subdir, localName)
+	file := filepath.Join(cachePath, subdir, entry.File)
 	return WordList{
-		Sentences: sentences,
-		Name:      name,
+		Sentences: entry.Sentences,
+		Name:      entry.Name,
 		Path:      file,
 		RemoteURI: uri,
-		Enabled:   enabled,
+		Language:  entry.Language,
+		Checksum:  entry.Sha256,
+		Enabled:   entry.Enabled,
 		synced:    fileExists(file),
-	}
+	}, nil
 }
 
-func defaultConfig() Config {
+// Lists and layouts of the catalog in its order, enabled lists stay enabled.
+// Ones dropped from the catalog are kept after them, they may still be synced.
+// Entries with unsafe file names are skipped.
+func applyCatalog(config Config, catalog words.Catalog) Config {
 	cachePath := getCachePath()
-	defaultLayout := defaultLayoutFile(cachePath, "Qwerty", "")
 
+	enabled := map[string]bool{}
+	for _, list := range config.WordLists {
+		enabled[list.Name] = list.Enabled
+	}
+	var wordLists []WordList
+	listed := map[string]bool{}
+	for _, entry := range catalog.WordLists {
+		list, err := catalogWordList(cachePath, entry)
+		if err != nil {
+			continue
+		}
+		if wasEnabled, ok := enabled[list.Name]; ok {
+			list.Enabled = wasEnabled
+		}
+		wordLists = append(wordLists, list)
+		listed[list.Name] = true
+	}
+	for _, list := range config.WordLists {
+		if !listed[list.Name] {
+			wordLists = append(wordLists, list)
+		}
+	}
+	config.WordLists = wordLists
+
+	layouts := []LayoutFile{{Name: "Qwerty"}}
+	listed = map[string]bool{"Qwerty": true}
+	for _, entry := range catalog.Layouts {
+		layout, err := catalogLayoutFile(cachePath, entry)
+		if err != nil {
+			continue
+		}
+		layouts = append(layouts, layout)
+		listed[entry.Name] = true
+	}
+	for _, layout := range config.LayoutFiles {
+		if !listed[layout.Name] {
+			layouts = append(layouts, layout)
+		}
+	}
+	config.LayoutFiles = layouts
+
+	return config
+}
+
+func defaultConfig() Config {
 	return Config{
 		TestSettingCursors: initTestSettingCursors(),
 		Version:            currentConfigVersion,
 		EmbededWordLists: []EmbededWordList{
-			{"Common words", false, true},
-			{"Frankenstein sentences", true, true},
-		},
-		WordLists: []WordList{
-			defaultWordList(cachePath, "Frankenstein words", "frankenstein.json", true, false),
-
-			defaultWordList(cachePath, "Dorian Gray words", "dorian-gray.json", true, false),
-			defaultWordList(cachePath, "Dorian gray sentences", "dorian-gray.json", true, true),
-
-			defaultWordList(cachePath, "Pride and Prejudice words", "pride-and-prejudice.json", true, false),
-			defaultWordList(cachePath, "Pride and Prejudice sentences", "pride-and-prejudice.json", true, true),
-
-			defaultWordList(cachePath, "Sherlock Holmes words", "sherlock-holmes.json", true, false),
-			defaultWordList(cachePath, "Sherlock Holmes sentences", "sherlock-holmes.json", true, true),
-
-			defaultWordList(cachePath, "Dracula words", "dracula.json", true, false),
-			defaultWordList(cachePath, "Dracula sentences", "dracula.json", true, true),
-
-			defaultWordList(cachePath, "The Yellow Wallpaper words", "the-yellow-wallpaper.json", true, false),
-			defaultWordList(cachePath, "The Yellow Wallpaper sentences", "the-yellow-wallpaper.json", true, true),
-
-			defaultWordList(cachePath, "A Tale of Two Cities words", "a-tale-of-two-cities.json", true, false),
-			defaultWordList(cachePath, "A Tale of Two Cities sentences", "a-tale-of-two-cities.json", true, true),
-
-			defaultWordList(cachePath, "The Great Gatsby words", "the-great-gatsby.json", true, false),
-			defaultWordList(cachePath, "The Great Gatsby sentences", "the-great-gatsby.json", true, true),
-
-			defaultWordList(cachePath, "The Count of Monte Cristo words", "the-count-of-monte-cristo.json", true, false),
-			defaultWordList(cachePath, "The Count of Monte Cristo sentences", "the-count-of-monte-cristo.json", true, true),
-
-			defaultWordList(cachePath, "Treasure Island words", "treasure-island.json", true, false),
-			defaultWordList(cachePath, "Treasure Island sentences", "treasure-island.json", true, true),
-
-			defaultWordList(cachePath, "Little Women words", "little-women.json", true, false),
-			defaultWordList(cachePath, "Little Women sentences", "little-women.json", true, true),
-
-			defaultWordList(cachePath, "Peter Pan words", "peter-pan.json", true, false),
-			defaultWordList(cachePath, "Peter Pan sentences", "peter-pan.json", true, true),
-		},
-		LayoutFiles: []LayoutFile{
-			defaultLayoutFile("", "Qwerty", ""),
-			defaultLayoutFile(cachePath, "Dvorak", "dvorak.json"),
-			defaultLayoutFile(cachePath, "Colemak DH", "colemak-dh.json"),
-			defaultLayoutFile(cachePath, "Gallium", "gallium.json"),
+			{"Common words", false, true, "en"},
+			{"Frankenstein sentences", true, true, "en"},
+			{"Common German words", false, false, "de"},
+			{"Common Hebrew words", false, false, "he"},
+			{"Common Lithuanian words", false, false, "lt"},
+			{"Common Russian words", false, false, "ru"},
 		},
-		Layout: retrieveLayout(defaultLayout),
+		LayoutFiles: []LayoutFile{{Name: "Qwerty"}},
+		Layout:      Layout{Name: "Qwerty"},
 	}
 }
@@ -0,0 +1,300 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type editorMode int
+
+const (
+	editorBrowsing editorMode = iota
+	editorAssigning
+	editorNaming
+)
+
+// Keyboard grid for tweaking a layout key by key
+type LayoutEditor struct {
+	source     Layout
+	outputs    map[rune]rune // What each Qwerty key types, shifted ones included
+	row        int
+	column     int
+	shifted    bool
+	marked     rune // Physical key waiting to be swapped with the one under the cursor, 0 when none
+	mode       editorMode
+	name       []rune
+	problem    string
+	saved      string
+	err        error
+	configView ConfigView
+}
+
+func initLayoutEditor(layout Layout, configView ConfigView) LayoutEditor {
+	outputs := map[rune]rune{}
+	for _, key := range qwertyKeys {
+		outputs[key] = layout.remap(key, false)
+	}
+
+	return LayoutEditor{
+		source:     layout,
+		outputs:    outputs,
+		row:        1,
+		configView: configView,
+	}
+}
+
+// Layout under the config view cursor, or the selected one
+func (configView ConfigView) editedLayout() Layout {
+	wordListSectionEnd := len(configView.config.EmbededWordLists) + len(configView.config.WordLists)
+	if at := configView.cursor - wordListSectionEnd; at >= 0 && at < len(configView.config.LayoutFiles) {
+		if layout, err := configView.config.LayoutFiles[at].getLayout(); err == nil {
+			return layout
+		}
+	}
+
+	return configView.config.Layout
+}
+
+func (editor LayoutEditor) handleInput(msg tea.Msg, state State) State {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return state
+	}
+
+	action := editor.configView.config.keymap.action(editorContext, key)
+	typed := key.Type == tea.KeyRunes && !key.Paste
+	switch {
+	case editor.saved != "":
+		if action == selectAction || action == menuAction {
+			configView := initConfigView(ReadConfig(), editor.configView.mainMenu)
+			configView.cursor = editor.configView.cursor
+			return configView
+		}
+	case editor.mode == editorAssigning:
+		switch {
+		case typed:
+			editor = editor.assign(key.Runes[0])
+		case action == menuAction || action == selectAction:
+			editor.mode = editorBrowsing
+		}
+	case editor.mode == editorNaming:
+		switch {
+		case action == selectAction:
+			editor.saved, editor.err = editor.save()
+		case action == menuAction:
+			editor.err = nil
+			editor.mode = editorBrowsing
+		case action == deleteLetterAction:
+			editor.err = nil
+			if len(editor.name) > 0 {
+				editor.name = editor.name[:len(editor.name)-1]
+			}
+		case typed, key.Type == tea.KeySpace:
+			editor.err = nil
+			editor.name = append(editor.name, key.Runes...)
+		}
+	default:
+		editor.problem = ""
+		switch action {
+		case menuAction:
+			return editor.configView
+		case upAction:
+			editor.row = (editor.row + len(qwertyRows) - 1) % len(qwertyRows)
+		case downAction:
+			editor.row = (editor.row + 1) % len(qwertyRows)
+		case leftAction:
+			editor.column--
+		case rightAction:
+			editor.column++
+		case shiftLayerAction:
+			editor.shifted = !editor.shifted
+		case selectAction:
+			editor.mode = editorAssigning
+		case deleteLetterAction:
+			editor.outputs[editor.key()] = editor.key()
+		case toggleAction:
+			editor = editor.swap()
+		case syncAction:
+			if duplicates := editor.duplicates(); len(duplicates) > 0 {
+				editor.problem = fmt.Sprintf("%s typed by more than one key, fix them before saving", string(duplicates))
+			} else {
+				editor.mode = editorNaming
+				editor.name = []rune(editor.defaultName())
+			}
+		}
+		width := len([]rune(qwertyRows[editor.row]))
+		editor.column = (editor.column + width) % width
+	}
+
+	return editor
+}
+
+// Physical key under the cursor, on the shown layer
+func (editor LayoutEditor) key() rune {
+	physical := []rune(qwertyRows[editor.row])[editor.column]
+	if editor.shifted {
+		return qwertyShifted(physical)
+	}
+
+	return physical
+}
+
+// Keys move with both of their layers
+func (editor LayoutEditor) swap() LayoutEditor {
+	physical := []rune(qwertyRows[editor.row])[editor.column]
+	if editor.marked == 0 {
+		editor.marked = physical
+		return editor
+	}
+
+	for _, pair := range [][2]rune{{editor.marked, physical}, {qwertyShifted(editor.marked), qwertyShifted(physical)}} {
+		editor.outputs[pair[0]], editor.outputs[pair[1]] = editor.outputs[pair[1]], editor.outputs[pair[0]]
+	}
+	editor.marked = 0
+
+	return editor
+}
+
+// Letters get the other case on the other layer, as long as that one still types a letter
+func (editor LayoutEditor) assign(r rune) LayoutEditor {
+	physical := []rune(qwertyRows[editor.row])[editor.column]
+	editor.outputs[editor.key()] = r
+
+	other, otherCase := qwertyShifted(physical), unicode.ToUpper(r)
+	if editor.shifted {
+		other, otherCase = physical, unicode.ToLower(r)
+	}
+	if otherCase != r && unicode.IsLetter(editor.outputs[other]) {
+		editor.outputs[other] = otherCase
+	}
+	editor.mode = editorBrowsing
+
+	return editor
+}
+
+// Characters typed by more than one key, a layout has to be bijective to be typed back
+func (editor LayoutEditor) duplicates() []rune {
+	count := map[rune]int{}
+	for _, output := range editor.outputs {
+		count[output]++
+	}
+
+	var acc []rune
+	for output, n := range count {
+		if n > 1 {
+			acc = append(acc, output)
+		}
+	}
+	sort.Slice(acc, func(i, j int) bool { return acc[i] < acc[j] })
+
+	return acc
+}
+
+func (editor LayoutEditor) defaultName() string {
+	for _, layoutFile := range editor.configView.config.LayoutFiles {
+		if layoutFile.Name == editor.source.displayName() && checkLayoutName(editor.configView.config, layoutFile.Name) == nil {
+			return layoutFile.Name
+		}
+	}
+
+	return editor.source.displayName() + " custom"
+}
+
+func (editor LayoutEditor) layout() Layout {
+	layout := Layout{
+		Name:        strings.TrimSpace(string(editor.name)),
+		Mappings:    map[rune]rune{},
+		AltMappings: editor.source.AltMappings,
+		Sequences:   editor.source.Sequences,
+	}
+	for key, output := range editor.outputs {
+		if key != output {
+			layout.Mappings[key] = output
+		}
+	}
+
+	return layout
+}
+
+func (editor LayoutEditor) save() (string, error) {
+	layout := editor.layout()
+	if layout.Name == "" {
+		return "", fmt.Errorf("the layout needs a name")
+	}
+	if err := checkLayoutName(editor.configView.config, layout.Name); err != nil {
+		return "", err
+	}
+
+	return saveLocalLayout(layout)
+}
+
+func (m model) layoutEditorView(editor LayoutEditor) string {
+	var view string
+	back := style(fmt.Sprintf("%s back to config", m.keymap.key(selectAction)), m.styles.toEnter)
+
+	switch {
+	case editor.saved != "":
+		view = fmt.Sprintf("Saved %s to %s\n\n", style(editor.layout().Name, m.styles.runningTimer), editor.saved) + back
+	case editor.mode == editorNaming:
+		if editor.err != nil {
+			view = style(editor.err.Error(), m.styles.mistakes) + "\n\n"
+		}
+		view += fmt.Sprintf("name: %s%s\n\n", string(editor.name), style(" ", m.styles.cursor)) +
+			style(fmt.Sprintf("%s save, %s back to editing", m.keymap.key(selectAction), m.keymap.key(menuAction)), m.styles.toEnter)
+	default:
+		duplicates := editor.duplicates()
+		current := editor.key()
+		keyboard := renderKeyboard(Layout{}, staggeredKeyboard, 4, func(physical rune, _ rune) string {
+			key := physical
+			if editor.shifted {
+				key = qwertyShifted(physical)
+			}
+			output := editor.outputs[key]
+			cell := fmt.Sprintf(" %c ", output)
+
+			switch {
+			case key == current && editor.mode == editorAssigning:
+				return style(" _ ", m.styles.cursor)
+			case key == current:
+				return style(cell, m.styles.cursor)
+			case physical == editor.marked:
+				return style(cell, m.styles.runningTimer)
+			case strings.ContainsRune(string(duplicates), output):
+				return style(cell, m.styles.mistakes)
+			case output != key:
+				return style(cell, m.styles.correct)
+			}
+			return style(cell, m.styles.toEnter)
+		})
+
+		layer := "plain"
+		if editor.shifted {
+			layer = "shift"
+		}
+		status := style("mappings are bijective", m.styles.correct)
+		switch {
+		case editor.problem != "":
+			status = style(editor.problem, m.styles.mistakes)
+		case len(duplicates) > 0:
+			status = style(fmt.Sprintf("%s typed by more than one key", string(duplicates)), m.styles.mistakes)
+		}
+
+		help := fmt.Sprintf("%s assign, %s swap, %s reset key, %s layer, %s save, %s to config",
+			m.keymap.key(selectAction), m.keymap.key(toggleAction), m.keymap.key(deleteLetterAction),
+			m.keymap.key(shiftLayerAction), m.keymap.key(syncAction), m.keymap.key(menuAction))
+		if editor.mode == editorAssigning {
+			help = fmt.Sprintf("type the character for %c, %s cancel", current, m.keymap.key(menuAction))
+		}
+
+		view = fmt.Sprintf("%s, %s layer\n\n%s\n\n%s\n\n", editor.source.displayName(), layer, keyboard, status) + style(help, m.styles.toEnter)
+	}
+
+	view = lipgloss.NewStyle().Align(lipgloss.Center).Render("Edit layout\n\n" + view)
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, view)
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+type InputEventKind byte
+
+const (
+	runeEvent       InputEventKind = iota // Letter entered into the input
+	pendingEvent                          // Key of an unfinished layout sequence
+	spaceEvent                            // Space entered into the input
+	backspaceEvent                        // Backspace, including dropping a pending key
+	deleteWordEvent                       // ctrl+w
+)
+
+type InputEvent struct {
+	At       time.Duration // Since the test has started
+	Kind     InputEventKind
+	Expected rune // What should have been entered at the cursor, 0 past the end
+	Typed    rune // Key as the terminal sent it
+	Mapped   rune // What the layout turned the key into
+	Cursor   int  // Position in the words to enter before the event
+}
+
+type keystrokeLog []InputEvent
+
+const keystrokeLogVersion = 1
+
+func (base *TestBase) record(kind InputEventKind, typed rune, mapped rune) {
+	// Timestamps count from when the timer or stopwatch has started, nothing is typed before that
+	if base.startedAt.IsZero() {
+		return
+	}
+	now := time.Now()
+
+	var expected rune
+	cursor := len(base.inputBuffer)
+	if cursor < len(base.wordsToEnter) {
+		expected = base.wordsToEnter[cursor]
+	}
+
+	base.events = append(base.events, InputEvent{
+		At:       now.Sub(base.startedAt) - base.pausedFor,
+		Kind:     kind,
+		Expected: expected,
+		Typed:    typed,
+		Mapped:   mapped,
+		Cursor:   cursor,
+	})
+}
+
+// Events are stored as varints, timestamps and cursor positions as deltas from the previous event.
+// That keeps a log of a usual test at a few bytes per keystroke.
+func (log keystrokeLog) encode() string {
+	buf := []byte{keystrokeLogVersion}
+	var previous InputEvent
+
+	for _, event := range log {
+		buf = append(buf, byte(event.Kind))
+		buf = binary.AppendUvarint(buf, uint64(event.At.Milliseconds()-previous.At.Milliseconds()))
+		buf = binary.AppendVarint(buf, int64(event.Cursor-previous.Cursor))
+		buf = binary.AppendUvarint(buf, uint64(event.Expected))
+		buf = binary.AppendUvarint(buf, uint64(event.Typed))
+		buf = binary.AppendUvarint(buf, uint64(event.Mapped))
+		previous = event
+	}
+
+	return base64.RawStdEncoding.EncodeToString(buf)
+}
+
+func decodeKeystrokeLog(encoded string) (keystrokeLog, error) {
+	buf, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	if len(buf) == 0 || buf[0] != keystrokeLogVersion {
+		return nil, errors.New("unknown keystroke log version")
+	}
+
+	var log keystrokeLog
+	var previous InputEvent
+	at := 1
+
+	readUvarint := func() uint64 {
+		value, n := binary.Uvarint(buf[at:])
+		if n <= 0 {
+			err = errors.New("corrupted keystroke log")
+			return 0
+		}
+		at += n
+		return value
+	}
+	readVarint := func() int64 {
+		value, n := binary.Varint(buf[at:])
+		if n <= 0 {
+			err = errors.New("corrupted keystroke log")
+			return 0
+		}
+		at += n
+		return value
+	}
+
+	for at < len(buf) && err == nil {
+		event := InputEvent{Kind: InputEventKind(buf[at])}
+		at++
+		event.At = previous.At + time.Duration(readUvarint())*time.Millisecond
+		event.Cursor = previous.Cursor + int(readVarint())
+		event.Expected = rune(readUvarint())
+		event.Typed = rune(readUvarint())
+		event.Mapped = rune(readUvarint())
+
+		log = append(log, event)
+		previous = event
+	}
+
+	return log, err
+}
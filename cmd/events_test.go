@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestKeystrokeLogRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		log  keystrokeLog
+	}{
+		{
+			name: "single letter",
+			log: keystrokeLog{
+				{At: 0, Kind: runeEvent, Expected: 'a', Typed: 'a', Mapped: 'a', Cursor: 0},
+			},
+		},
+		{
+			name: "mistake fixed with backspace",
+			log: keystrokeLog{
+				{At: 120 * time.Millisecond, Kind: runeEvent, Expected: 'h', Typed: 'j', Mapped: 'h', Cursor: 0},
+				{At: 250 * time.Millisecond, Kind: runeEvent, Expected: 'i', Typed: 'u', Mapped: 'o', Cursor: 1},
+				{At: 400 * time.Millisecond, Kind: backspaceEvent, Expected: 0, Cursor: 2},
+				{At: 530 * time.Millisecond, Kind: runeEvent, Expected: 'i', Typed: 'u', Mapped: 'i', Cursor: 1},
+				{At: 700 * time.Millisecond, Kind: spaceEvent, Expected: ' ', Typed: ' ', Mapped: ' ', Cursor: 2},
+			},
+		},
+		{
+			name: "word deleted, cursor moving back",
+			log: keystrokeLog{
+				{At: time.Second, Kind: runeEvent, Expected: 'x', Typed: 'y', Mapped: 'y', Cursor: 40},
+				{At: time.Second + 90*time.Millisecond, Kind: deleteWordEvent, Expected: 'z', Cursor: 41},
+				{At: 3 * time.Minute, Kind: runeEvent, Expected: 'w', Typed: 'w', Mapped: 'w', Cursor: 35},
+			},
+		},
+		{
+			name: "pending key and runes beyond one byte",
+			log: keystrokeLog{
+				{At: 10 * time.Millisecond, Kind: pendingEvent, Expected: 'ą', Typed: '`', Mapped: '`', Cursor: 3},
+				{At: 20 * time.Millisecond, Kind: runeEvent, Expected: 'ą', Typed: 'a', Mapped: 'ą', Cursor: 3},
+				{At: 30 * time.Millisecond, Kind: runeEvent, Expected: '日', Typed: '日', Mapped: '日', Cursor: 4},
+				{At: 40 * time.Millisecond, Kind: runeEvent, Expected: '😀', Typed: '😀', Mapped: '😀', Cursor: 5},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, err := decodeKeystrokeLog(tt.log.encode())
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if !reflect.DeepEqual(decoded, tt.log) {
+				t.Errorf("got %+v, want %+v", decoded, tt.log)
+			}
+		})
+	}
+}
+
+func TestDecodeKeystrokeLogErrors(t *testing.T) {
+	encoded, _ := base64.RawStdEncoding.DecodeString(keystrokeLog{
+		{At: 120 * time.Millisecond, Kind: runeEvent, Expected: 'a', Typed: 'a', Mapped: 'a', Cursor: 0},
+	}.encode())
+	truncated := base64.RawStdEncoding.EncodeToString(encoded[:len(encoded)-1])
+
+	tests := []struct {
+		name    string
+		encoded string
+	}{
+		{name: "empty", encoded: ""},
+		{name: "not base64", encoded: "!!"},
+		{name: "unknown version", encoded: "Ag"},
+		{name: "truncated", encoded: truncated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeKeystrokeLog(tt.encoded); err == nil {
+				t.Errorf("decoded %q without an error", tt.encoded)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rivo/uniseg"
+)
+
+// No input for this long means the typist was away
+const afkThreshold = 7 * time.Second
+
+// More letters than this in a single key message can't come from typing
+const pasteGraphemeThreshold = 8
+
+// Pasted text, either reported by the terminal or arriving faster than anyone types
+func isPaste(msg tea.KeyMsg) bool {
+	return msg.Paste || uniseg.GraphemeClusterCount(string(msg.Runes)) > pasteGraphemeThreshold
+}
+
+// Reasons why the result should not be compared with others, empty for a clean one
+func (base TestBase) flags(elapsed time.Duration) []string {
+	var acc []string
+
+	longestIdle := time.Duration(0)
+	previous := time.Duration(0)
+	for _, event := range base.events {
+		longestIdle = max(longestIdle, event.At-previous)
+		previous = event.At
+	}
+	// Timer tests run on after the last key press
+	longestIdle = max(longestIdle, elapsed-previous)
+
+	if longestIdle >= afkThreshold {
+		acc = append(acc, fmt.Sprintf("idle for %s", longestIdle.Round(time.Second)))
+	}
+	if base.pasted {
+		acc = append(acc, "pasted input")
+	}
+
+	return acc
+}
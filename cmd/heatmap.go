@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"time"
+	"unicode"
+)
+
+type resultsPanelKind int
+
+const (
+	graphPanel resultsPanelKind = iota
+	errorRatePanel
+	errorsPanel
+	latencyPanel
+	wordsPanel
+)
+
+var resultsPanelNames = []string{"speed", "errors per second", "key errors", "key latency", "words"}
+
+// Longer gaps are thinking, not typing
+const maxKeyLatency = 2 * time.Second
+
+type keyStat struct {
+	presses    int
+	mistakes   int
+	latency    time.Duration // Sum over latencyCnt presses
+	latencyCnt int
+}
+
+type keyStats map[rune]*keyStat
+
+// Switchable part of the results screen
+type ResultsPanel struct {
+	kind       resultsPanelKind
+	historical bool
+	current    keyStats
+	history    keyStats
+	words      []WordStat
+	errors     []float64 // Each second
+}
+
+func newResultsPanel(results Results, persisted PersistentResults) ResultsPanel {
+	history := keyStats{}
+	for _, log := range persisted.keystrokeLogs() {
+		history.add(log)
+	}
+
+	current := keyStats{}
+	current.add(results.events)
+
+	return ResultsPanel{current: current, history: history, words: results.words, errors: results.metrics.errorsEachSecond}
+}
+
+func (panel ResultsPanel) next() ResultsPanel {
+	panel.kind = (panel.kind + 1) % resultsPanelKind(len(resultsPanelNames))
+	return panel
+}
+
+func (panel ResultsPanel) toggleHistorical() ResultsPanel {
+	panel.historical = !panel.historical
+	return panel
+}
+
+func (panel ResultsPanel) stats() keyStats {
+	if panel.historical {
+		return panel.history
+	}
+
+	return panel.current
+}
+
+// Attributes each entered letter to the key that should have been pressed
+func (stats keyStats) add(log keystrokeLog) {
+	for idx, event := range log {
+		if event.Kind != runeEvent && event.Kind != spaceEvent || event.Expected == 0 {
+			continue
+		}
+
+		stat := stats.of(event.Expected)
+		stat.presses++
+		if event.Mapped != event.Expected {
+			stat.mistakes++
+		}
+
+		if idx > 0 {
+			latency := event.At - log[idx-1].At
+			if latency < maxKeyLatency {
+				stat.latency += latency
+				stat.latencyCnt++
+			}
+		}
+	}
+}
+
+func (stats keyStats) of(key rune) *keyStat {
+	key = unicode.ToLower(key)
+	if stats[key] == nil {
+		stats[key] = &keyStat{}
+	}
+
+	return stats[key]
+}
+
+func (stat keyStat) errorRate() float64 {
+	return float64(stat.mistakes) / float64(stat.presses)
+}
+
+func (stat keyStat) averageLatency() time.Duration {
+	return stat.latency / time.Duration(stat.latencyCnt)
+}
+
+func (stats keyStats) medianLatency() time.Duration {
+	var latencies []time.Duration
+	for _, stat := range stats {
+		if stat.latencyCnt > 0 {
+			latencies = append(latencies, stat.averageLatency())
+		}
+	}
+	if len(latencies) == 0 {
+		return 0
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	return latencies[len(latencies)/2]
+}
+
+// Index into the heat scale, -1 when there is nothing to show for the key
+func (panel ResultsPanel) heat(key rune, median time.Duration) int {
+	stat := panel.stats()[unicode.ToLower(key)]
+
+	switch {
+	case stat == nil || stat.presses == 0:
+		return -1
+	case panel.kind == errorsPanel:
+		return bucket(stat.errorRate(), []float64{0, 0.02, 0.05, 0.1, 0.2})
+	case stat.latencyCnt == 0 || median == 0:
+		return -1
+	default:
+		return bucket(float64(stat.averageLatency())/float64(median), []float64{0.8, 1, 1.2, 1.5, 2})
+	}
+}
+
+// Number of thresholds the value exceeds
+func bucket(value float64, thresholds []float64) int {
+	idx := 0
+	for idx < len(thresholds) && value > thresholds[idx] {
+		idx++
+	}
+
+	return idx
+}
+
+func (m model) resultsPanelView(panel ResultsPanel, layout Layout, plotData []float64, width int) string {
+	switch panel.kind {
+	case graphPanel:
+		return plotWpms(plotData, width)
+	case errorRatePanel:
+		return plotErrors(panel.errors, width)
+	case wordsPanel:
+		return m.wordsPanelView(panel)
+	}
+
+	median := panel.stats().medianLatency()
+	keyboard := renderKeyboard(layout, staggeredKeyboard, 4, func(_ rune, label rune) string {
+		cell := fmt.Sprintf(" %c ", label)
+		heat := panel.heat(label, median)
+		if heat < 0 {
+			return style(cell, m.styles.toEnter)
+		}
+
+		return style(cell, m.styles.heat[heat])
+	})
+
+	scope := "this test"
+	if panel.historical {
+		scope = "all tests"
+	}
+	caption := style(fmt.Sprintf("%s, %s (%s: switch view, %s: this test/all tests)", resultsPanelNames[panel.kind], scope, m.keymap.key(switchViewAction), m.keymap.key(historyAction)), m.styles.toEnter)
+
+	return "\n" + keyboard + "\n\n" + caption + "\n"
+}
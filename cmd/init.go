@@ -10,14 +10,21 @@ import (
 	"github.com/muesli/termenv"
 )
 
+// A stale catalog is refreshed in the background, the outcome is shown in the config view
 func (m model) Init() tea.Cmd {
-	return nil
+	return func() tea.Msg {
+		if cachePath := getCachePath(); words.CatalogStale(cachePath) {
+			return catalogRefreshedMsg{err: words.RefreshCatalog(cachePath)}
+		}
+		return nil
+	}
 }
 
 // todo: clean these up. Maybe we could reuse filtering by enabled and synce, because now it's redundant
 type WordsSelection struct {
 	name         string
 	generatorKey string
+	language     string
 }
 
 func filterEnabledWordSelection(config Config) []WordsSelection {
@@ -27,6 +34,7 @@ func filterEnabledWordSelection(config Config) []WordsSelection {
 			acc = append(acc, WordsSelection{
 				name:         elem.Name,
 				generatorKey: elem.Path,
+				language:     elem.Language,
 			})
 		}
 	}
@@ -35,6 +43,7 @@ func filterEnabledWordSelection(config Config) []WordsSelection {
 			acc = append(acc, WordsSelection{
 				name:         elem.Name,
 				generatorKey: elem.Name,
+				language:     elem.Language,
 			})
 		}
 	}
@@ -49,6 +58,7 @@ func filterEnabledSentenceSelection(config Config) []WordsSelection {
 			acc = append(acc, WordsSelection{
 				name:         elem.Name,
 				generatorKey: elem.Path,
+				language:     elem.Language,
 			})
 		}
 	}
@@ -58,6 +68,7 @@ func filterEnabledSentenceSelection(config Config) []WordsSelection {
 			acc = append(acc, WordsSelection{
 				name:         elem.Name,
 				generatorKey: elem.Name,
+				language:     elem.Language,
 			})
 		}
 	}
@@ -71,6 +82,7 @@ func filterEnabledSelections(config Config) []WordsSelection {
 			acc = append(acc, WordsSelection{
 				name:         elem.Name,
 				generatorKey: elem.Path,
+				language:     elem.Language,
 			})
 		}
 	}
@@ -80,6 +92,7 @@ func filterEnabledSelections(config Config) []WordsSelection {
 			acc = append(acc, WordsSelection{
 				name:         elem.Name,
 				generatorKey: elem.Name,
+				language:     elem.Language,
 			})
 		}
 	}
@@ -96,16 +109,7 @@ func initTimerBasedTest(settings TimerBasedTestSettings, mainMenu MainMenu) Time
 			isRunning: false,
 			timedout:  false,
 		},
-		base: TestBase{
-			wordsToEnter: mainMenu.timeBasedGenerator.Generate(settings.wordListSelections[settings.wordListCursor].generatorKey),
-			inputBuffer:  make([]rune, 0),
-			rawInputCnt:  0,
-			mistakes: mistakes{
-				mistakesAt:     make(map[int]bool, 0),
-				rawMistakesCnt: 0,
-			},
-			cursor: 0,
-		},
+		base:      initTestBase(mainMenu.timeBasedGenerator, settings.wordListSelections[settings.wordListCursor], mainMenu.config),
 		completed: false,
 		mainMenu:  mainMenu,
 	}
@@ -119,21 +123,31 @@ func initWordCountBasedTest(settings WordCountBasedTestSettings, mainMenu MainMe
 			stopwatch: stopwatch.New(),
 			isRunning: false,
 		},
-		base: TestBase{
-			wordsToEnter: mainMenu.wordCountGenerator.Generate(settings.wordListSelections[settings.wordListCursor].generatorKey),
-			inputBuffer:  make([]rune, 0),
-			rawInputCnt:  0,
-			mistakes: mistakes{
-				mistakesAt:     make(map[int]bool, 0),
-				rawMistakesCnt: 0,
-			},
-			cursor: 0,
-		},
+		base:      initTestBase(mainMenu.wordCountGenerator, settings.wordListSelections[settings.wordListCursor], mainMenu.config),
 		completed: false,
 		mainMenu:  mainMenu,
 	}
 }
 
+func initPracticeTest(practice []string, settings WordCountBasedTestSettings, mainMenu MainMenu) WordCountBasedTest {
+	test := initWordCountBasedTest(settings, mainMenu)
+	test.base.wordsToEnter = practiceText(practice)
+	test.practice = practice
+
+	return test
+}
+
+// Practice tests run as word count tests, they need its settings even when started from elsewhere
+func (menu MainMenu) wordCountSettings() (WordCountBasedTestSettings, bool) {
+	for _, selection := range menu.selections {
+		if settings, ok := selection.(WordCountBasedTestSettings); ok && settings.Enabled() {
+			return settings, true
+		}
+	}
+
+	return WordCountBasedTestSettings{}, false
+}
+
 func initSentenceCountBasedTest(settings SentenceCountBasedTestSettings, mainMenu MainMenu) SentenceCountBasedTest {
 	mainMenu.sentenceCountGenerator.Count = settings.sentenceCountSelections[settings.sentenceCountCursor]
 	return SentenceCountBasedTest{
@@ -142,21 +156,39 @@ func initSentenceCountBasedTest(settings SentenceCountBasedTestSettings, mainMen
 			stopwatch: stopwatch.New(),
 			isRunning: false,
 		},
-		base: TestBase{
-			wordsToEnter: mainMenu.sentenceCountGenerator.Generate(settings.sentenceListSelections[settings.sentenceListCursor].generatorKey),
-			inputBuffer:  make([]rune, 0),
-			rawInputCnt:  0,
-			mistakes: mistakes{
-				mistakesAt:     make(map[int]bool, 0),
-				rawMistakesCnt: 0,
-			},
-			cursor: 0,
-		},
+		base:      initTestBase(mainMenu.sentenceCountGenerator, settings.sentenceListSelections[settings.sentenceListCursor], mainMenu.config),
 		completed: false,
 		mainMenu:  mainMenu,
 	}
 }
 
+func initTestBase(generator words.WordsGenerator, selection WordsSelection, config Config) TestBase {
+	languageCode := selection.language
+	if languageCode == "" {
+		languageCode = generator.Language(selection.generatorKey)
+	}
+	language := config.language(languageCode)
+
+	return TestBase{
+		wordsToEnter: generator.Generate(selection.generatorKey),
+		inputBuffer:  make([]rune, 0),
+		rawInputCnt:  0,
+		mistakes: mistakes{
+			mistakesAt:     make(map[int]bool, 0),
+			omittedAt:      make(map[int]bool, 0),
+			rawMistakesCnt: 0,
+		},
+		cursor:       0,
+		rtl:          generator.Direction(selection.generatorKey, language.Direction) == words.RightToLeft,
+		charsPerWord: language.CharsPerWord,
+		formula:      config.wpmFormula(),
+		skipWords:    config.SpaceSkipsWord,
+		overflow:     make(map[int][]rune),
+		aligned:      make(map[int]alignedWord),
+		gate:         newStartGate(config.StartTrigger),
+	}
+}
+
 func initTestSettingCursors() TestSettingCursors {
 	return TestSettingCursors{
 		TimerTimeCursor:             2,
@@ -230,6 +262,7 @@ func initMainMenu() MainMenu {
 			initTimerBasedTestSettings(config, timeBasedWordSelections),
 			initWordCountBasedTestSettings(config, countBasedWordSelections),
 			initSentenceCountBasedTestSettings(config, countBasedSentenceSelections),
+			StatsViewSelection{},
 			initConfigViewSelection(),
 		},
 		cursor:                 0,
@@ -251,10 +284,12 @@ func paths(selections []WordsSelection) []string {
 }
 
 func initialModel(profile termenv.Profile, fore termenv.Color, width, height int) model {
+	mainMenu := initMainMenu()
 	return model{
 		width:  width,
 		height: height,
-		state:  initMainMenu(),
+		state:  mainMenu,
+		keymap: mainMenu.config.keymap,
 		styles: Styles{
 			correct: func(str string) termenv.Style {
 				return termenv.String(str).Foreground(fore)
@@ -268,6 +303,9 @@ func initialModel(profile termenv.Profile, fore termenv.Color, width, height int
 			cursor: func(str string) termenv.Style {
 				return termenv.String(str).Reverse().Bold()
 			},
+			pending: func(str string) termenv.Style {
+				return termenv.String(str).Reverse().Underline()
+			},
 			runningTimer: func(str string) termenv.Style {
 				return termenv.String(str).Foreground(profile.Color("2"))
 			},
@@ -280,6 +318,33 @@ func initialModel(profile termenv.Profile, fore termenv.Color, width, height int
 			faintGreen: func(str string) termenv.Style {
 				return termenv.String(str).Foreground(profile.Color("10")).Faint()
 			},
+			heat:    reversedStyles(profile, "2", "10", "3", "11", "9", "1"),
+			fingers: foregroundStyles(profile, "5", "4", "6", "2", "3"),
+			nextKey: reversedStyles(profile, "5", "4", "6", "2", "3"),
 		},
 	}
 }
+
+func foregroundStyles(profile termenv.Profile, colors ...string) []StringStyle {
+	var acc []StringStyle
+	for _, color := range colors {
+		color := profile.Color(color)
+		acc = append(acc, func(str string) termenv.Style {
+			return termenv.String(str).Foreground(color)
+		})
+	}
+
+	return acc
+}
+
+func reversedStyles(profile termenv.Profile, colors ...string) []StringStyle {
+	var acc []StringStyle
+	for _, color := range colors {
+		color := profile.Color(color)
+		acc = append(acc, func(str string) termenv.Style {
+			return termenv.String(str).Foreground(color).Reverse()
+		})
+	}
+
+	return acc
+}
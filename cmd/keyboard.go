@@ -0,0 +1,121 @@
+package cmd
+
+import "strings"
+
+// Physical keys of an ANSI keyboard, named after what they produce on Qwerty
+var qwertyRows = []string{
+	"`1234567890-=",
+	"qwertyuiop[]\\",
+	"asdfghjkl;'",
+	"zxcvbnm,./",
+}
+
+// The same keys pressed with shift
+var qwertyShiftedRows = []string{
+	"~!@#$%^&*()_+",
+	"QWERTYUIOP{}|",
+	"ASDFGHJKL:\"",
+	"ZXCVBNM<>?",
+}
+
+// Every key of the keyboard, then the same keys with shift
+var qwertyKeys = append([]rune(strings.Join(qwertyRows, "")), []rune(strings.Join(qwertyShiftedRows, ""))...)
+
+// Finger typing each key, from the left pinky (0) to the right pinky (7)
+var qwertyFingers = []string{
+	"0012334456777",
+	"0123344567777",
+	"01233445677",
+	"0123344567",
+}
+
+type KeyboardGeometry string
+
+const (
+	noKeyboard          KeyboardGeometry = "off"
+	staggeredKeyboard   KeyboardGeometry = "staggered"
+	ortholinearKeyboard KeyboardGeometry = "ortholinear"
+	splitKeyboard       KeyboardGeometry = "split"
+)
+
+var keyboardGeometries = []KeyboardGeometry{noKeyboard, staggeredKeyboard, ortholinearKeyboard, splitKeyboard}
+
+type keyboardShape struct {
+	offsets []int // Of each row, in half keys
+	halves  []int // Keys of each row on the left half, nil when the keyboard is not split
+}
+
+var keyboardShapes = map[KeyboardGeometry]keyboardShape{
+	staggeredKeyboard:   {offsets: []int{0, 1, 1, 2}},
+	ortholinearKeyboard: {offsets: []int{0, 2, 2, 2}},
+	splitKeyboard:       {offsets: []int{0, 2, 2, 2}, halves: []int{6, 5, 5, 5}},
+}
+
+// What the physical key produces with the given layout
+func (layout Layout) keyLabel(physical rune) rune {
+	return layout.remap(physical, false)
+}
+
+// What the physical key produces on Qwerty with shift held, 0 when it is not on the keyboard
+func qwertyShifted(physical rune) rune {
+	for idx, row := range qwertyRows {
+		if at := strings.IndexRune(row, physical); at >= 0 {
+			return []rune(qwertyShiftedRows[idx])[at]
+		}
+	}
+
+	return 0
+}
+
+func rowOf(physical rune) int {
+	for idx, row := range qwertyRows {
+		if strings.ContainsRune(row, physical) {
+			return idx
+		}
+	}
+
+	return -1
+}
+
+func fingerOf(physical rune) int {
+	for idx, row := range qwertyRows {
+		if at := strings.IndexRune(row, physical); at >= 0 {
+			return int(qwertyFingers[idx][at] - '0')
+		}
+	}
+
+	return -1
+}
+
+// Draws the keyboard row by row, letting keyFn render each key cell. keyWidth is the width of a cell with the space after it.
+func renderKeyboard(layout Layout, geometry KeyboardGeometry, keyWidth int, keyFn func(physical rune, label rune) string) string {
+	shape, ok := keyboardShapes[geometry]
+	if !ok {
+		shape = keyboardShapes[staggeredKeyboard]
+	}
+
+	var lines []string
+	for idx, row := range qwertyRows {
+		var line strings.Builder
+		line.WriteString(strings.Repeat(" ", shape.offsets[idx]*keyWidth/2))
+		for at, physical := range []rune(row) {
+			if shape.halves != nil && at == shape.halves[idx] {
+				line.WriteString(strings.Repeat(" ", keyWidth))
+			}
+			line.WriteString(keyFn(physical, layout.keyLabel(physical)))
+			line.WriteRune(' ')
+		}
+		lines = append(lines, line.String())
+	}
+
+	// Rows are padded to the same width so that centering keeps the stagger
+	widest := 0
+	for _, line := range lines {
+		widest = max(widest, stringWidth(line))
+	}
+	for idx, line := range lines {
+		lines[idx] = line + strings.Repeat(" ", widest-stringWidth(line))
+	}
+
+	return strings.Join(lines, "\n")
+}
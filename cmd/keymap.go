@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type Action string
+
+const (
+	noAction           Action = ""
+	quitAction         Action = "quit"
+	menuAction         Action = "menu"
+	restartAction      Action = "restart"
+	deleteLetterAction Action = "deleteLetter"
+	deleteWordAction   Action = "deleteWord"
+	pauseAction        Action = "pause"
+	startAction        Action = "start"
+	upAction           Action = "up"
+	downAction         Action = "down"
+	leftAction         Action = "left"
+	rightAction        Action = "right"
+	selectAction       Action = "select"
+	toggleAction       Action = "toggle"
+	syncAction         Action = "sync"
+	switchViewAction   Action = "switchView"
+	historyAction      Action = "history"
+	practiceAction     Action = "practice"
+	calibrateAction    Action = "calibrate"
+	skipAction         Action = "skip"
+	editLayoutAction   Action = "editLayout"
+	shiftLayerAction   Action = "shiftLayer"
+	catalogAction      Action = "catalog"
+)
+
+type keyContext string
+
+const (
+	testContext    keyContext = "test"
+	menuContext    keyContext = "menu"
+	configContext  keyContext = "config"
+	resultsContext keyContext = "results"
+	statsContext   keyContext = "stats"
+	wizardContext  keyContext = "wizard"
+	editorContext  keyContext = "editor"
+)
+
+// Actions that can be triggered in each context, a key may be bound to only one of them there
+var contextActions = map[keyContext][]Action{
+	testContext:    {quitAction, menuAction, restartAction, deleteLetterAction, deleteWordAction, pauseAction, startAction},
+	menuContext:    {quitAction, menuAction, upAction, downAction, leftAction, rightAction, selectAction},
+	configContext:  {quitAction, menuAction, upAction, downAction, toggleAction, syncAction, calibrateAction, editLayoutAction, catalogAction},
+	resultsContext: {quitAction, menuAction, restartAction, selectAction, switchViewAction, historyAction, practiceAction},
+	statsContext:   {quitAction, menuAction, leftAction, rightAction},
+	wizardContext:  {quitAction, menuAction, deleteLetterAction, selectAction, skipAction},
+	editorContext:  {quitAction, menuAction, upAction, downAction, leftAction, rightAction, selectAction, toggleAction, syncAction, deleteLetterAction, shiftLayerAction},
+}
+
+type KeymapPreset = map[Action][]string
+
+var defaultKeymap = KeymapPreset{
+	quitAction:         {"ctrl+c"},
+	menuAction:         {"esc", "ctrl+q"},
+	restartAction:      {"ctrl+r"},
+	deleteLetterAction: {"backspace", "ctrl+h"},
+	deleteWordAction:   {"ctrl+w", "alt+backspace"},
+	pauseAction:        {"ctrl+p"},
+	startAction:        {"enter"},
+	upAction:           {"up", "k"},
+	downAction:         {"down", "j"},
+	leftAction:         {"left", "h"},
+	rightAction:        {"right", "l", "tab"},
+	selectAction:       {"enter"},
+	toggleAction:       {"e"},
+	syncAction:         {"s"},
+	switchViewAction:   {"tab"},
+	historyAction:      {"a"},
+	practiceAction:     {"p"},
+	calibrateAction:    {"c"},
+	skipAction:         {"tab"},
+	editLayoutAction:   {"m"},
+	shiftLayerAction:   {"shift+tab"},
+	catalogAction:      {"u"},
+}
+
+var keymapPresets = map[string]KeymapPreset{
+	"default": defaultKeymap,
+	// Ctrl+Backspace deletes a word as in editors outside the terminal. Terminals that send ctrl+h for it
+	// can't tell it from backspace there, so ctrl+h deletes a word too.
+	"windows": withBindings(defaultKeymap, KeymapPreset{
+		deleteLetterAction: {"backspace"},
+		deleteWordAction:   {ctrlBackspace, "ctrl+w", "alt+backspace"},
+	}),
+	"emacs": withBindings(defaultKeymap, KeymapPreset{
+		menuAction:       {"ctrl+g", "ctrl+q"},
+		deleteWordAction: {"alt+backspace", "ctrl+w"},
+		upAction:         {"up", "ctrl+p"},
+		downAction:       {"down", "ctrl+n"},
+		leftAction:       {"left", "ctrl+b"},
+		rightAction:      {"right", "ctrl+f", "tab"},
+	}),
+}
+
+var keyAliases = map[string]string{
+	"space": " ",
+}
+
+// Many terminals send ctrl+h for backspace, some send it for ctrl+backspace. It stays
+// backspace unless ctrl+backspace is bound in the user's own bindings.
+const ctrlBackspace = "ctrl+backspace"
+
+type KeymapConfig struct {
+	Preset   string              `toml:"preset"`
+	Bindings map[string][]string `toml:"bindings"`
+}
+
+type Keymap struct {
+	bindings KeymapPreset
+	byKey    map[keyContext]map[string]Action
+}
+
+func withBindings(preset KeymapPreset, overrides KeymapPreset) KeymapPreset {
+	acc := KeymapPreset{}
+	for action, keys := range preset {
+		acc[action] = keys
+	}
+	for action, keys := range overrides {
+		acc[action] = keys
+	}
+
+	return acc
+}
+
+func newKeymap(cfg KeymapConfig) (Keymap, error) {
+	presetName := cfg.Preset
+	if presetName == "" {
+		presetName = "default"
+	}
+	preset, ok := keymapPresets[presetName]
+	if !ok {
+		var names []string
+		for name := range keymapPresets {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return Keymap{}, fmt.Errorf("unknown keymap preset %q, expected one of %s", presetName, strings.Join(names, ", "))
+	}
+
+	overrides := KeymapPreset{}
+	ctrlBackspaceBound := false
+	for name, keys := range cfg.Bindings {
+		if _, ok := defaultKeymap[Action(name)]; !ok {
+			return Keymap{}, fmt.Errorf("unknown keymap action %q", name)
+		}
+		overrides[Action(name)] = keys
+		ctrlBackspaceBound = ctrlBackspaceBound || slices.Contains(keys, ctrlBackspace)
+	}
+	if _, ok := overrides[deleteLetterAction]; ctrlBackspaceBound && !ok {
+		overrides[deleteLetterAction] = slices.DeleteFunc(slices.Clone(preset[deleteLetterAction]), func(key string) bool { return key == "ctrl+h" })
+	}
+
+	keymap := Keymap{
+		bindings: withBindings(preset, overrides),
+		byKey:    map[keyContext]map[string]Action{},
+	}
+
+	var conflicts []string
+	for context, actions := range contextActions {
+		keymap.byKey[context] = map[string]Action{}
+		for _, action := range actions {
+			for _, key := range keymap.bindings[action] {
+				key = normalizeKey(key)
+				if key == ctrlBackspace {
+					key = "ctrl+h"
+				}
+				if other, ok := keymap.byKey[context][key]; ok && other != action {
+					conflicts = append(conflicts, fmt.Sprintf("%q is bound to both %s and %s in %s", key, other, action, context))
+				}
+				if (context == testContext || context == wizardContext) && isTypingKey(key) {
+					conflicts = append(conflicts, fmt.Sprintf("%q of %s would be typed in %s", key, action, context))
+				}
+				keymap.byKey[context][key] = action
+			}
+		}
+	}
+
+	if len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		return Keymap{}, fmt.Errorf("keymap conflicts: %s", strings.Join(conflicts, "; "))
+	}
+
+	return keymap, nil
+}
+
+func normalizeKey(key string) string {
+	if alias, ok := keyAliases[key]; ok {
+		return alias
+	}
+
+	return key
+}
+
+func isTypingKey(key string) bool {
+	return len([]rune(key)) == 1
+}
+
+// Action the key press triggers in the given context
+func (keymap Keymap) action(context keyContext, msg tea.Msg) Action {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		return keymap.byKey[context][msg.String()]
+	}
+
+	return noAction
+}
+
+// First key of the action, for help texts
+func (keymap Keymap) key(action Action) string {
+	if keys := keymap.bindings[action]; len(keys) > 0 {
+		return keys[0]
+	}
+
+	return "unbound"
+}
@@ -0,0 +1,631 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	xunicode "golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+const (
+	xkbFormat    = "xkb"
+	klcFormat    = "klc"
+	qmkFormat    = "qmk"
+	kmonadFormat = "kmonad"
+)
+
+var layoutFormats = []string{xkbFormat, klcFormat, qmkFormat, kmonadFormat}
+
+// What a key produces alone, with shift, with AltGr and with shift+AltGr, 0 where nothing.
+// The AltGr levels end up in the Alt layer.
+type keyLevels [4]rune
+
+type importedLayout struct {
+	layout   Layout
+	problems []string // Keys left unmapped and source entries that could not be converted
+}
+
+func detectLayoutFormat(path string, data []byte) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".klc":
+		return klcFormat, nil
+	case ".kbd":
+		return kmonadFormat, nil
+	case ".c":
+		return qmkFormat, nil
+	}
+
+	switch {
+	case bytes.Contains(data, []byte("xkb_symbols")):
+		return xkbFormat, nil
+	case bytes.Contains(data, []byte("defsrc")):
+		return kmonadFormat, nil
+	// KLC files have a LAYOUT section too
+	case bytes.HasPrefix(data, []byte{0xff, 0xfe}), bytes.Contains(data, []byte("SHIFTSTATE")):
+		return klcFormat, nil
+	case bytes.Contains(data, []byte("\"layers\"")), bytes.Contains(data, []byte("LAYOUT")):
+		return qmkFormat, nil
+	}
+
+	return "", fmt.Errorf("can't tell the format of %s, pass one of %s with --format", path, strings.Join(layoutFormats, ", "))
+}
+
+// Converts a layout definition into typioca's Layout. An empty name is taken from the source or the file name.
+func importLayout(path string, data []byte, format string, name string, variant string) (importedLayout, error) {
+	var keys map[rune]keyLevels
+	var sourceName string
+	var problems []string
+	var err error
+
+	switch format {
+	case xkbFormat:
+		keys, sourceName, problems, err = parseXkb(string(data), variant)
+	case klcFormat:
+		keys, sourceName, problems, err = parseKlc(data)
+	case qmkFormat:
+		keys, problems, err = parseQmk(data)
+	case kmonadFormat:
+		keys, problems, err = parseKmonad(string(data))
+	default:
+		err = fmt.Errorf("unknown layout format %q, expected one of %s", format, strings.Join(layoutFormats, ", "))
+	}
+	if err != nil {
+		return importedLayout{}, err
+	}
+
+	if name == "" {
+		name = sourceName
+	}
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	return buildLayout(name, keys, problems), nil
+}
+
+func buildLayout(name string, keys map[rune]keyLevels, problems []string) importedLayout {
+	layout := Layout{
+		Name:        name,
+		Mappings:    map[rune]rune{},
+		AltMappings: map[rune]rune{},
+	}
+
+	var unmapped []string
+	for _, row := range qwertyRows {
+		for _, physical := range row {
+			levels, ok := keys[physical]
+			if !ok || levels[0] == 0 {
+				unmapped = append(unmapped, string(physical))
+				continue
+			}
+
+			shifted := qwertyShifted(physical)
+			if levels[0] != physical {
+				layout.Mappings[physical] = levels[0]
+			}
+			if levels[1] != 0 && levels[1] != shifted {
+				layout.Mappings[shifted] = levels[1]
+			}
+			if levels[2] != 0 {
+				layout.AltMappings[physical] = levels[2]
+			}
+			if levels[3] != 0 {
+				layout.AltMappings[shifted] = levels[3]
+			}
+		}
+	}
+
+	if len(layout.AltMappings) == 0 {
+		layout.AltMappings = nil
+	}
+	if len(unmapped) > 0 {
+		problems = append(problems, "unmapped keys, left as on Qwerty: "+strings.Join(unmapped, " "))
+	}
+
+	return importedLayout{layout: layout, problems: problems}
+}
+
+// XKB key names of the ANSI keys, by what they produce on Qwerty
+var xkbKeys = map[string]rune{"TLDE": '`', "BKSL": '\\', "AC12": '\\'}
+
+func init() {
+	for prefix, row := range map[string]string{"AE": "1234567890-=", "AD": "qwertyuiop[]", "AC": "asdfghjkl;'", "AB": "zxcvbnm,./"} {
+		for idx, physical := range row {
+			xkbKeys[fmt.Sprintf("%s%02d", prefix, idx+1)] = physical
+		}
+	}
+}
+
+var (
+	xkbSymbolsBlock = regexp.MustCompile(`xkb_symbols\s+"([^"]*)"\s*\{`)
+	xkbKeyLine      = regexp.MustCompile(`key\s*<(\w+)>\s*\{([^}]*)\}`)
+	xkbLevels       = regexp.MustCompile(`\[([^\[\]]*)\]`)
+	xkbName         = regexp.MustCompile(`name\[\w+\]\s*=\s*"([^"]*)"`)
+	xkbInclude      = regexp.MustCompile(`include\s+"([^"]*)"`)
+	unicodeKeysym   = regexp.MustCompile(`^U\+?([0-9A-Fa-f]{4,6})$`)
+)
+
+func parseXkb(source string, variant string) (map[rune]keyLevels, string, []string, error) {
+	blocks := xkbSymbolsBlock.FindAllStringSubmatchIndex(source, -1)
+	if len(blocks) == 0 {
+		return nil, "", nil, fmt.Errorf("no xkb_symbols block found")
+	}
+
+	start, end := -1, len(source)
+	var variants []string
+	for idx, block := range blocks {
+		blockVariant := source[block[2]:block[3]]
+		variants = append(variants, blockVariant)
+		if start < 0 && (variant == "" || variant == blockVariant) {
+			start = block[1]
+			if idx+1 < len(blocks) {
+				end = blocks[idx+1][0]
+			}
+		}
+	}
+	if start < 0 {
+		return nil, "", nil, fmt.Errorf("no variant %q, found %s", variant, strings.Join(variants, ", "))
+	}
+	body := source[start:end]
+
+	var name string
+	if match := xkbName.FindStringSubmatch(body); match != nil {
+		name = match[1]
+	}
+
+	var problems []string
+	for _, match := range xkbInclude.FindAllStringSubmatch(body, -1) {
+		problems = append(problems, fmt.Sprintf("include %q is not followed, import it separately", match[1]))
+	}
+
+	keys := map[rune]keyLevels{}
+	for _, match := range xkbKeyLine.FindAllStringSubmatch(body, -1) {
+		physical, ok := xkbKeys[match[1]]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("<%s> is not on an ANSI keyboard", match[1]))
+			continue
+		}
+
+		var levels keyLevels
+		for _, group := range xkbLevels.FindAllStringSubmatch(match[2], -1) {
+			if strings.HasPrefix(group[1], "Group") {
+				continue
+			}
+			for level, keysym := range strings.Split(group[1], ",") {
+				if level >= len(levels) {
+					break
+				}
+				r, problem := xkbKeysym(strings.TrimSpace(keysym))
+				if problem != "" {
+					problems = append(problems, fmt.Sprintf("<%s> level %d: %s", match[1], level+1, problem))
+				}
+				levels[level] = r
+			}
+			break
+		}
+		keys[physical] = levels
+	}
+
+	return keys, name, problems, nil
+}
+
+func xkbKeysym(keysym string) (rune, string) {
+	switch {
+	case keysym == "" || keysym == "NoSymbol" || keysym == "VoidSymbol":
+		return 0, ""
+	case len([]rune(keysym)) == 1:
+		return []rune(keysym)[0], ""
+	case strings.HasPrefix(keysym, "dead_"):
+		return 0, "dead key " + keysym + " skipped, add it to sequences by hand"
+	}
+
+	if match := unicodeKeysym.FindStringSubmatch(keysym); match != nil {
+		code, _ := strconv.ParseInt(match[1], 16, 32)
+		return rune(code), ""
+	}
+	if code, err := strconv.ParseInt(strings.TrimPrefix(keysym, "0x"), 16, 32); strings.HasPrefix(keysym, "0x") && err == nil {
+		// Unicode keysyms are the code point with 0x01000000 added, Latin-1 ones are the code point itself
+		switch {
+		case code >= 0x01000100 && code <= 0x0110ffff:
+			return rune(code - 0x01000000), ""
+		case code >= 0x20 && code <= 0x7e, code >= 0xa0 && code <= 0xff:
+			return rune(code), ""
+		}
+	}
+	if r, ok := xkbKeysyms[keysym]; ok {
+		return r, ""
+	}
+
+	return 0, "unknown keysym " + keysym
+}
+
+// KLC scan codes of the ANSI keys
+var klcScanCodes = map[int64]rune{0x29: '`', 0x2b: '\\'}
+
+func init() {
+	for first, row := range map[int64]string{0x02: "1234567890-=", 0x10: "qwertyuiop[]", 0x1e: "asdfghjkl;'", 0x2c: "zxcvbnm,./"} {
+		for idx, physical := range row {
+			klcScanCodes[first+int64(idx)] = physical
+		}
+	}
+}
+
+// KLC shift states that map to a level, Ctrl alone is left out
+var klcShiftStates = map[int]int{0: 0, 1: 1, 6: 2, 7: 3}
+
+func parseKlc(data []byte) (map[rune]keyLevels, string, []string, error) {
+	// KLC files are usually saved as UTF-16
+	decoded, _, err := transform.Bytes(xunicode.BOMOverride(xunicode.UTF8.NewDecoder()), data)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	var name string
+	var shiftStates []int // Level of each column after Cap, -1 when the column is not used
+	var problems []string
+	keys := map[rune]keyLevels{}
+	section := ""
+
+	for _, line := range strings.Split(string(decoded), "\n") {
+		fields := strings.Fields(line)
+		for idx, field := range fields {
+			if strings.HasPrefix(field, "//") {
+				fields = fields[:idx]
+				break
+			}
+		}
+		if len(fields) == 0 {
+			continue
+		}
+
+		if isKlcKeyword(fields[0]) {
+			section = fields[0]
+			if section == "KBD" && len(fields) > 2 {
+				name = strings.Trim(strings.Join(fields[2:], " "), `"`)
+			}
+			continue
+		}
+
+		switch section {
+		case "SHIFTSTATE":
+			state, err := strconv.Atoi(fields[0])
+			if err != nil {
+				return nil, "", nil, fmt.Errorf("bad shift state %q", fields[0])
+			}
+			level, ok := klcShiftStates[state]
+			if !ok {
+				level = -1
+			}
+			shiftStates = append(shiftStates, level)
+		case "LAYOUT":
+			scanCode, err := strconv.ParseInt(fields[0], 16, 32)
+			if err != nil || len(fields) < 3 {
+				continue
+			}
+			physical, ok := klcScanCodes[scanCode]
+			if !ok {
+				continue
+			}
+
+			var levels keyLevels
+			for column, value := range fields[3:] {
+				if column >= len(shiftStates) || shiftStates[column] < 0 {
+					continue
+				}
+				r, problem := klcValue(value)
+				if problem != "" {
+					problems = append(problems, fmt.Sprintf("scan code %02x column %d: %s", scanCode, column+1, problem))
+				}
+				levels[shiftStates[column]] = r
+			}
+			keys[physical] = levels
+		}
+	}
+
+	if len(shiftStates) == 0 {
+		return nil, "", nil, fmt.Errorf("no SHIFTSTATE section found")
+	}
+
+	return keys, name, problems, nil
+}
+
+func isKlcKeyword(field string) bool {
+	for _, r := range field {
+		if !unicode.IsUpper(r) && r != '_' {
+			return false
+		}
+	}
+
+	return len(field) > 1
+}
+
+func klcValue(value string) (rune, string) {
+	switch {
+	case value == "-1":
+		return 0, ""
+	case value == "%%":
+		return 0, "ligature skipped"
+	case strings.HasSuffix(value, "@"):
+		return 0, "dead key " + strings.TrimSuffix(value, "@") + " skipped, add it to sequences by hand"
+	case len([]rune(value)) == 1:
+		return []rune(value)[0], ""
+	}
+
+	code, err := strconv.ParseInt(value, 16, 32)
+	if err != nil {
+		return 0, "unknown value " + value
+	}
+
+	return rune(code), ""
+}
+
+// Keys of a 60% ANSI board in the order QMK lists them, 0 for those that type nothing
+var ansi60Keys = []rune("`1234567890-=\x00" + "\x00qwertyuiop[]\\" + "\x00asdfghjkl;'\x00" + "\x00zxcvbnm,./\x00" + "\x00\x00\x00\x00\x00\x00\x00\x00")
+
+var qmkKeycodes = map[string]rune{
+	"GRV": '`', "GRAVE": '`', "MINS": '-', "MINUS": '-', "EQL": '=', "EQUAL": '=', "LBRC": '[', "RBRC": ']',
+	"BSLS": '\\', "SCLN": ';', "QUOT": '\'', "COMM": ',', "DOT": '.', "SLSH": '/', "SPC": ' ',
+	"TILD": '~', "EXLM": '!', "AT": '@', "HASH": '#', "DLR": '$', "PERC": '%', "CIRC": '^', "AMPR": '&',
+	"ASTR": '*', "LPRN": '(', "RPRN": ')', "UNDS": '_', "PLUS": '+', "LCBR": '{', "RCBR": '}', "PIPE": '|',
+	"COLN": ':', "DQUO": '"', "DQT": '"', "LT": '<', "GT": '>', "QUES": '?',
+}
+
+var qmkLayoutCall = regexp.MustCompile(`LAYOUT\w*\s*\(`)
+
+// Only the base layer is read. QMK keycodes are sent as Qwerty keys, so the shift layer is Qwerty's.
+func parseQmk(data []byte) (map[rune]keyLevels, []string, error) {
+	var keycodes []string
+
+	var keymap struct {
+		Layers [][]string `json:"layers"`
+	}
+	if err := json.Unmarshal(data, &keymap); err == nil {
+		if len(keymap.Layers) == 0 {
+			return nil, nil, fmt.Errorf("keymap has no layers")
+		}
+		keycodes = keymap.Layers[0]
+	} else {
+		source := string(data)
+		call := qmkLayoutCall.FindStringIndex(source)
+		if call == nil {
+			return nil, nil, fmt.Errorf("no LAYOUT(...) found")
+		}
+		keycodes = splitArguments(source[call[1]:])
+	}
+
+	if len(keycodes) != len(ansi60Keys) {
+		return nil, nil, fmt.Errorf("expected the %d keys of a 60%% ANSI board, got %d", len(ansi60Keys), len(keycodes))
+	}
+
+	keys := map[rune]keyLevels{}
+	var problems []string
+	for idx, keycode := range keycodes {
+		physical := ansi60Keys[idx]
+		if physical == 0 {
+			continue
+		}
+
+		r, shifted := qmkKeycode(keycode)
+		if r == 0 {
+			problems = append(problems, fmt.Sprintf("%s on the %c key types nothing", keycode, physical))
+			continue
+		}
+		keys[physical] = withQwertyShift(r, shifted)
+	}
+
+	return keys, problems, nil
+}
+
+// Top level arguments of a call, from right after its opening parenthesis
+func splitArguments(source string) []string {
+	var acc []string
+	depth, start := 0, 0
+	for idx, r := range source {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 {
+				return append(acc, strings.TrimSpace(source[start:idx]))
+			}
+			depth--
+		case ',':
+			if depth == 0 {
+				acc = append(acc, strings.TrimSpace(source[start:idx]))
+				start = idx + 1
+			}
+		}
+	}
+
+	return acc
+}
+
+// Keys whose arguments are layers, not keycodes
+var qmkLayerSwitches = map[string]bool{
+	"MO": true, "TG": true, "TO": true, "TT": true, "DF": true, "PDF": true, "OSL": true, "LM": true,
+}
+
+// What the keycode types and whether shift is part of it. Mod-taps and layer-taps type their last argument,
+// layer switches type nothing.
+func qmkKeycode(keycode string) (rune, bool) {
+	if open := strings.IndexRune(keycode, '('); open >= 0 && strings.HasSuffix(keycode, ")") {
+		wrapper := keycode[:open]
+		if qmkLayerSwitches[wrapper] {
+			return 0, false
+		}
+		arguments := splitArguments(keycode[open+1:])
+		r, shifted := qmkKeycode(arguments[len(arguments)-1])
+		return r, shifted || wrapper == "S" || wrapper == "LSFT" || wrapper == "RSFT"
+	}
+
+	name := strings.TrimPrefix(keycode, "KC_")
+	if len(name) == 1 && (unicode.IsUpper(rune(name[0])) || unicode.IsDigit(rune(name[0]))) {
+		return unicode.ToLower(rune(name[0])), false
+	}
+	if r, ok := qmkKeycodes[name]; ok {
+		return r, qwertyShifted(r) == 0 && r != ' '
+	}
+
+	return 0, false
+}
+
+// Levels of a key sending the given Qwerty key
+func withQwertyShift(r rune, shifted bool) keyLevels {
+	if shifted {
+		if shiftedR := qwertyShifted(r); shiftedR != 0 {
+			r = shiftedR
+		}
+		return keyLevels{r, r}
+	}
+
+	return keyLevels{r, qwertyShifted(r)}
+}
+
+var kmonadKeys = map[string]rune{
+	"grv": '`', "min": '-', "minus": '-', "eql": '=', "equal": '=', "lbrc": '[', "rbrc": ']',
+	"bksl": '\\', `\\`: '\\', "scln": ';', "apos": '\'', "quot": '\'', "comm": ',', "dot": '.', "slsh": '/',
+}
+
+var (
+	kmonadLineComment  = regexp.MustCompile(`;;.*`)
+	kmonadBlockComment = regexp.MustCompile(`(?s)#\|.*?\|#`)
+)
+
+// Pairs defsrc with the first deflayer. kmonad sends Qwerty keys, so the shift layer is Qwerty's.
+func parseKmonad(source string) (map[rune]keyLevels, []string, error) {
+	source = kmonadBlockComment.ReplaceAllString(source, "")
+	source = kmonadLineComment.ReplaceAllString(source, "")
+
+	src := kmonadForm(source, "defsrc")
+	if src == nil {
+		return nil, nil, fmt.Errorf("no defsrc found")
+	}
+	layer := kmonadForm(source, "deflayer")
+	if len(layer) < 1 {
+		return nil, nil, fmt.Errorf("no deflayer found")
+	}
+	layer = layer[1:]
+	if len(layer) != len(src) {
+		return nil, nil, fmt.Errorf("defsrc has %d keys but the layer has %d", len(src), len(layer))
+	}
+
+	keys := map[rune]keyLevels{}
+	var problems []string
+	for idx, name := range src {
+		physical, _ := kmonadKey(name)
+		if physical == 0 || qwertyShifted(physical) == 0 {
+			continue
+		}
+
+		output := layer[idx]
+		switch {
+		case output == "_":
+			keys[physical] = withQwertyShift(physical, false)
+			continue
+		case output == "XX":
+			continue
+		case strings.HasPrefix(output, "@"):
+			problems = append(problems, fmt.Sprintf("alias %s on the %c key is not followed", output, physical))
+			continue
+		case strings.ContainsRune(output, '(') && !strings.HasPrefix(output, "\\"):
+			problems = append(problems, fmt.Sprintf("%s on the %c key is not followed", output, physical))
+			continue
+		}
+
+		r, shifted := kmonadKey(output)
+		if r == 0 {
+			problems = append(problems, fmt.Sprintf("%s on the %c key types nothing", output, physical))
+			continue
+		}
+		keys[physical] = withQwertyShift(r, shifted)
+	}
+
+	return keys, problems, nil
+}
+
+// Top level items of the first form with the given name, nested forms stay whole as single items
+func kmonadForm(source string, form string) []string {
+	at := 0
+	for {
+		start := strings.Index(source[at:], "("+form)
+		if start < 0 {
+			return nil
+		}
+		at += start + len(form) + 1
+		if at == len(source) || unicode.IsSpace(rune(source[at])) || source[at] == ')' {
+			break
+		}
+	}
+
+	var items []string
+	var item strings.Builder
+	depth := 0
+	quoted, escaped := false, false // Kmonad writes parens as keys with a backslash, \( and \)
+	for _, r := range source[at:] {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case quoted:
+			quoted = r != '"'
+		case r == '"':
+			quoted = true
+		case r == '(':
+			depth++
+		case r == ')' && depth == 0:
+			if item.Len() > 0 {
+				items = append(items, item.String())
+			}
+			return items
+		case r == ')':
+			depth--
+		case unicode.IsSpace(r) && depth == 0:
+			if item.Len() > 0 {
+				items = append(items, item.String())
+				item.Reset()
+			}
+			continue
+		}
+		item.WriteRune(r)
+	}
+
+	// The form is never closed
+	return nil
+}
+
+// The Qwerty key a kmonad key name stands for and whether shift is part of it
+func kmonadKey(name string) (rune, bool) {
+	if rest, ok := strings.CutPrefix(name, "\\"); ok && len([]rune(rest)) == 1 {
+		return kmonadKey(rest)
+	}
+	if rest, ok := strings.CutPrefix(name, "S-"); ok {
+		r, _ := kmonadKey(rest)
+		return r, r != 0
+	}
+	if r, ok := kmonadKeys[name]; ok {
+		return r, false
+	}
+
+	runes := []rune(name)
+	if len(runes) != 1 {
+		return 0, false
+	}
+	if unicode.IsUpper(runes[0]) {
+		return unicode.ToLower(runes[0]), true
+	}
+	for idx, row := range qwertyShiftedRows {
+		if at := strings.IndexRune(row, runes[0]); at >= 0 {
+			return []rune(qwertyRows[idx])[at], true
+		}
+	}
+
+	return runes[0], false
+}
@@ -0,0 +1,298 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	xunicode "golang.org/x/text/encoding/unicode"
+)
+
+const xkbFixture = `default partial alphanumeric_keys
+xkb_symbols "basic" {
+    include "us(basic)"
+    name[Group1] = "Test Dvorak";
+
+    key <AD01> { [ apostrophe, quotedbl ] };
+    key <AD02> { [ comma, less ] };
+    key <AD03> { [ period, greater, EuroSign ] };
+    key <AC01> { [ a, A, adiaeresis, Adiaeresis ] };
+    key <AB01> { [ semicolon, colon ] };
+    key <AE01> { [ 1, exclam, dead_acute ] };
+};
+
+xkb_symbols "other" {
+    key <AD01> { [ x, X ] };
+};
+`
+
+// Excerpts of the lt and ru symbols files, with keysyms beyond Latin-1
+const xkbLithuanianFixture = `partial alphanumeric_keys
+xkb_symbols "basic" {
+    name[Group1] = "Lithuanian";
+
+    key <AE01> { [ aogonek, Aogonek, 1, exclam ] };
+    key <AE02> { [ ccaron, Ccaron, 2, at ] };
+    key <AE04> { [ eabovedot, Eabovedot, 4, dollar ] };
+    key <AE06> { [ scaron, Scaron, 6, asciicircum ] };
+    key <AE08> { [ umacron, Umacron, 8, asterisk ] };
+    key <AE12> { [ zcaron, Zcaron, equal, plus ] };
+    key <AB10> { [ slash, question, 0x1002013, U+2014 ] };
+};
+`
+
+const xkbRussianFixture = `default partial alphanumeric_keys
+xkb_symbols "winkeys" {
+    name[Group1] = "Russian";
+
+    key <TLDE> { [ Cyrillic_io, Cyrillic_IO ] };
+    key <AE03> { [ 3, numerosign ] };
+    key <AD01> { [ Cyrillic_shorti, Cyrillic_SHORTI ] };
+    key <AD02> { [ Cyrillic_tse, Cyrillic_TSE ] };
+    key <AD11> { [ Cyrillic_ha, Cyrillic_HA ] };
+    key <AC01> { [ Cyrillic_ef, Cyrillic_EF ] };
+    key <AB09> { [ Cyrillic_yu, Cyrillic_YU ] };
+};
+`
+
+const klcFixture = `KBD	testdv	"Test KLC"
+
+SHIFTSTATE
+
+0	//Column 4
+1	//Column 5 : Shft
+2	//Column 6 :       Ctrl
+6	//Column 7 :       Ctrl Alt
+7	//Column 8 : Shft  Ctrl Alt
+
+LAYOUT		;an extra '@' at the end is a dead key
+
+//SC	VK_		Cap	0	1	2	6	7
+10	OEM_7		0	'	"	-1	-1	-1
+11	OEM_COMMA	0	,	<	-1	-1	-1
+1e	A		1	a	A	-1	e4	00c4
+12	OEM_PERIOD	0	.	>	-1	20ac	-1
+2c	OEM_1		0	;	:	-1	-1	-1
+02	1		0	1	!	-1	00b4@	-1
+
+KEYNAME
+
+01	Esc
+`
+
+const qmkFixture = `const uint16_t PROGMEM keymaps[][MATRIX_ROWS][MATRIX_COLS] = {
+    [0] = LAYOUT_60_ansi(
+        KC_GRV,  KC_1,    KC_2,    KC_3,    KC_4,    KC_5,    KC_6,    KC_7,    KC_8,    KC_9,    KC_0,    KC_LBRC, KC_RBRC, KC_BSPC,
+        KC_TAB,  KC_QUOT, KC_COMM, KC_DOT,  KC_P,    KC_Y,    KC_F,    KC_G,    KC_C,    KC_R,    KC_L,    KC_SLSH, KC_EQL,  MO(1),
+        KC_CAPS, LT(1, KC_A), KC_O, KC_E,   KC_U,    KC_I,    KC_D,    KC_H,    KC_T,    KC_N,    KC_S,    KC_MINS,          KC_ENT,
+        KC_LSFT,          KC_SCLN, KC_Q,    KC_J,    KC_K,    KC_X,    KC_B,    KC_M,    KC_W,    KC_V,    S(KC_Z),          KC_RSFT,
+        KC_LCTL, KC_LGUI, KC_LALT,                            KC_SPC,                             KC_RALT, MO(1),   KC_APP,  KC_RCTL
+    ),
+    [1] = LAYOUT_60_ansi(
+        _______, KC_F1,   KC_F2,   KC_F3,   KC_F4,   KC_F5,   KC_F6,   KC_F7,   KC_F8,   KC_F9,   KC_F10,  KC_F11,  KC_F12,  KC_DEL,
+        _______, _______, _______, _______, _______, _______, _______, _______, _______, _______, _______, _______, _______, _______,
+        _______, _______, _______, _______, _______, _______, _______, _______, _______, _______, _______, _______,          _______,
+        _______,          _______, _______, _______, _______, _______, _______, _______, _______, _______, _______,          _______,
+        _______, _______, _______,                            _______,                            _______, _______, _______, _______
+    )
+};
+`
+
+const kmonadFixture = `(defcfg
+  input  (device-file "/dev/input/by-id/usb-kbd-event-kbd")
+  output (uinput-sink "kmonad (test)")
+)
+
+;; Home row mod on a, the rest is Dvorak
+(defsrc
+  q    w    e    r    t    y
+  a    s    d    f
+  \\   ;
+)
+
+(defalias nav (layer-toggle nav))
+
+(deflayer dvorak
+  '    ,    .    p    y    f
+  (tap-hold 200 a lctl)    o    e    u
+  XX   S-1
+)
+
+(deflayer nav
+  _    _    _    _    _    _
+  _    _    _    _
+  _    _
+)
+`
+
+func utf16Fixture(t *testing.T, source string) string {
+	encoded, err := xunicode.UTF16(xunicode.LittleEndian, xunicode.UseBOM).NewEncoder().String(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return encoded
+}
+
+func TestImportLayout(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		data     string
+		variant  string
+		wantName string
+		mappings map[rune]rune // Checked ones only
+		alt      map[rune]rune
+		unmapped []rune   // Keys left as on Qwerty
+		problems []string // Substrings of the reported problems
+		clean    bool     // No keysym is unknown
+	}{
+		{
+			name:     "xkb",
+			format:   xkbFormat,
+			data:     xkbFixture,
+			wantName: "Test Dvorak",
+			mappings: map[rune]rune{'q': '\'', 'Q': '"', 'w': ',', 'W': '<', 'e': '.', 'E': '>', 'z': ';', 'Z': ':'},
+			alt:      map[rune]rune{'a': 'ä', 'A': 'Ä', 'e': '€'},
+			unmapped: []rune{'a', 'A', '1', '!'},
+			problems: []string{`include "us(basic)" is not followed`, "dead key dead_acute"},
+		},
+		{
+			name:     "xkb variant",
+			format:   xkbFormat,
+			data:     xkbFixture,
+			variant:  "other",
+			wantName: "fixture",
+			mappings: map[rune]rune{'q': 'x', 'Q': 'X'},
+			unmapped: []rune{'w'},
+		},
+		{
+			name:     "xkb lithuanian",
+			format:   xkbFormat,
+			data:     xkbLithuanianFixture,
+			wantName: "Lithuanian",
+			mappings: map[rune]rune{'1': 'ą', '!': 'Ą', '2': 'č', '@': 'Č', '4': 'ė', '6': 'š', '8': 'ū', '=': 'ž', '+': 'Ž'},
+			alt:      map[rune]rune{'1': '1', '!': '!', '=': '=', '/': '–', '?': '—'},
+			clean:    true,
+		},
+		{
+			name:     "xkb russian",
+			format:   xkbFormat,
+			data:     xkbRussianFixture,
+			wantName: "Russian",
+			mappings: map[rune]rune{'`': 'ё', '~': 'Ё', '#': '№', 'q': 'й', 'Q': 'Й', 'w': 'ц', '[': 'х', '{': 'Х', 'a': 'ф', '.': 'ю'},
+			unmapped: []rune{'3'},
+			clean:    true,
+		},
+		{
+			name:     "klc",
+			format:   klcFormat,
+			data:     klcFixture,
+			wantName: "Test KLC",
+			mappings: map[rune]rune{'q': '\'', 'Q': '"', 'w': ',', 'W': '<', 'e': '.', 'E': '>', 'z': ';', 'Z': ':'},
+			alt:      map[rune]rune{'a': 'ä', 'A': 'Ä', 'e': '€'},
+			unmapped: []rune{'a', '1'},
+			problems: []string{"dead key 00b4 skipped"},
+		},
+		{
+			name:     "klc in UTF-16",
+			format:   klcFormat,
+			data:     utf16Fixture(t, klcFixture),
+			wantName: "Test KLC",
+			mappings: map[rune]rune{'q': '\'', 'Q': '"', 'w': ','},
+			alt:      map[rune]rune{'a': 'ä'},
+		},
+		{
+			name:     "qmk",
+			format:   qmkFormat,
+			data:     qmkFixture,
+			wantName: "fixture",
+			mappings: map[rune]rune{'q': '\'', 'Q': '"', 'w': ',', 'W': '<', 's': 'o', 'S': 'O', '-': '[', '_': '{', 'z': ';', '/': 'Z', '?': 'Z'},
+			unmapped: []rune{'a', 'A', '\\', '|'},
+			problems: []string{"MO(1) on the \\ key types nothing"},
+		},
+		{
+			name:     "qmk json",
+			format:   qmkFormat,
+			data:     `{"keyboard": "test", "layers": [["KC_GRV", "KC_1", "KC_2", "KC_3", "KC_4", "KC_5", "KC_6", "KC_7", "KC_8", "KC_9", "KC_0", "KC_MINS", "KC_EQL", "KC_BSPC", "KC_TAB", "KC_Q", "KC_W", "KC_F", "KC_P", "KC_G", "KC_J", "KC_L", "KC_U", "KC_Y", "KC_SCLN", "KC_LBRC", "KC_RBRC", "KC_BSLS", "KC_BSPC", "KC_A", "KC_R", "KC_S", "KC_T", "KC_D", "KC_H", "KC_N", "KC_E", "KC_I", "KC_O", "KC_QUOT", "KC_ENT", "KC_LSFT", "KC_Z", "KC_X", "KC_C", "KC_V", "KC_B", "KC_K", "KC_M", "KC_COMM", "KC_DOT", "KC_SLSH", "KC_RSFT", "KC_LCTL", "KC_LGUI", "KC_LALT", "KC_SPC", "KC_RALT", "MO(1)", "KC_APP", "KC_RCTL"]]}`,
+			wantName: "fixture",
+			mappings: map[rune]rune{'e': 'f', 'E': 'F', 'r': 'p', 's': 'r', 'd': 's', ';': 'o', ':': 'O'},
+		},
+		{
+			name:     "kmonad",
+			format:   kmonadFormat,
+			data:     kmonadFixture,
+			wantName: "fixture",
+			mappings: map[rune]rune{'q': '\'', 'Q': '"', 'w': ',', 'W': '<', 'r': 'p', 'y': 'f', 's': 'o', 'f': 'u', ';': '!', ':': '!'},
+			unmapped: []rune{'a', 'A', '\\'},
+			problems: []string{"(tap-hold 200 a lctl) on the a key is not followed"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			imported, err := importLayout("layouts/fixture", []byte(tt.data), tt.format, "", tt.variant)
+			if err != nil {
+				t.Fatalf("import: %v", err)
+			}
+			layout := imported.layout
+
+			if layout.Name != tt.wantName {
+				t.Errorf("name %q, want %q", layout.Name, tt.wantName)
+			}
+			for key, want := range tt.mappings {
+				if got := layout.Mappings[key]; got != want {
+					t.Errorf("%c types %q, want %q", key, got, want)
+				}
+			}
+			for key, want := range tt.alt {
+				if got := layout.AltMappings[key]; got != want {
+					t.Errorf("alt+%c types %q, want %q", key, got, want)
+				}
+			}
+			for _, key := range tt.unmapped {
+				if got, ok := layout.Mappings[key]; ok {
+					t.Errorf("%c types %q, want it left as on Qwerty", key, got)
+				}
+			}
+
+			problems := strings.Join(imported.problems, "\n")
+			for _, want := range tt.problems {
+				if !strings.Contains(problems, want) {
+					t.Errorf("problems %q, want one containing %q", imported.problems, want)
+				}
+			}
+			if tt.clean && strings.Contains(problems, "unknown keysym") {
+				t.Errorf("problems %q, want every keysym known", imported.problems)
+			}
+		})
+	}
+}
+
+func TestDetectLayoutFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		data string
+		want string
+	}{
+		{name: "xkb", path: "symbols/dvorak", data: xkbFixture, want: xkbFormat},
+		{name: "klc by extension", path: "dvorak.klc", data: "", want: klcFormat},
+		{name: "klc by content", path: "dvorak.txt", data: klcFixture, want: klcFormat},
+		{name: "klc in UTF-16", path: "dvorak", data: utf16Fixture(t, klcFixture), want: klcFormat},
+		{name: "qmk source", path: "keymap", data: qmkFixture, want: qmkFormat},
+		{name: "qmk json", path: "keymap.json", data: `{"layers": []}`, want: qmkFormat},
+		{name: "kmonad", path: "config", data: kmonadFixture, want: kmonadFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := detectLayoutFormat(tt.path, []byte(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"strings"
+)
+
+func (layout Layout) remap(input rune, alt bool) rune {
+	if alt {
+		if r, ok := layout.AltMappings[input]; ok {
+			return r
+		}
+	}
+
+	if r, ok := layout.Mappings[input]; ok {
+		return r
+	}
+
+	return input
+}
+
+// Feeds a remapped rune into the pending sequence. Returns runes that are ready to be
+// entered and whatever is still waiting for more keys.
+func (layout Layout) feed(pending []rune, input rune) ([]rune, []rune) {
+	if len(layout.Sequences) == 0 {
+		return []rune{input}, nil
+	}
+
+	pending = append(pending, input)
+	var ready []rune
+
+	for len(pending) > 0 {
+		if layout.isSequencePrefix(pending) {
+			return ready, pending
+		}
+
+		consumed, output := layout.longestSequence(pending)
+		ready = append(ready, output...)
+		pending = pending[consumed:]
+	}
+
+	return ready, nil
+}
+
+// Resolves pending keys without waiting for more, e.g. when space is pressed.
+func (layout Layout) flush(pending []rune) []rune {
+	var ready []rune
+
+	for len(pending) > 0 {
+		consumed, output := layout.longestSequence(pending)
+		ready = append(ready, output...)
+		pending = pending[consumed:]
+	}
+
+	return ready
+}
+
+// Is there a sequence which is longer than the given keys and starts with them?
+func (layout Layout) isSequencePrefix(keys []rune) bool {
+	prefix := string(keys)
+	for sequence := range layout.Sequences {
+		if len(sequence) > len(prefix) && strings.HasPrefix(sequence, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Finds the longest sequence matching the beginning of keys. Unmatched key is passed through as is.
+func (layout Layout) longestSequence(keys []rune) (int, []rune) {
+	for end := len(keys); end > 0; end-- {
+		if output, ok := layout.Sequences[string(keys[:end])]; ok {
+			return end, []rune(output)
+		}
+	}
+
+	return 1, keys[:1]
+}
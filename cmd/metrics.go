@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"math"
+	"time"
+)
+
+type SpeedMetrics struct {
+	speedEachSecond  []float64 // Letters entered within each second, not a running average
+	errorsEachSecond []float64
+	consistency      float64 // 100 minus the coefficient of variation of speedEachSecond, in percent
+	burst            float64 // Fastest second
+	corrected        int     // Mistyped letters fixed later on
+	uncorrected      int
+}
+
+func (base TestBase) metrics() SpeedMetrics {
+	var speed, errors []float64
+
+	for _, event := range base.events {
+		if event.Kind != runeEvent && event.Kind != spaceEvent {
+			continue
+		}
+
+		second := int(event.At / time.Second)
+		for len(speed) <= second {
+			speed = append(speed, 0)
+			errors = append(errors, 0)
+		}
+
+		counts := TypingCounts{Chars: 1, CorrectChars: 1, Keystrokes: 1}
+		_, gross := base.formula.speed(counts, base.charsPerWord, 1.0/60)
+		speed[second] += gross
+		if event.Mapped != event.Expected {
+			errors[second]++
+		}
+	}
+
+	// The last second is usually cut short, it would drag both consistency and burst down
+	full := speed
+	if len(full) > 1 {
+		full = full[:len(full)-1]
+	}
+
+	uncorrected := base.errorCounts().total()
+
+	return SpeedMetrics{
+		speedEachSecond:  speed,
+		errorsEachSecond: errors,
+		consistency:      consistency(full),
+		burst:            maxOf(full),
+		corrected:        max(0, base.mistakes.rawMistakesCnt-base.positionalMistakes()),
+		uncorrected:      uncorrected,
+	}
+}
+
+func consistency(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
+	mean := sum / float64(len(values))
+	if mean == 0 {
+		return 0
+	}
+
+	var squares float64
+	for _, value := range values {
+		squares += (value - mean) * (value - mean)
+	}
+	deviation := math.Sqrt(squares / float64(len(values)))
+
+	return math.Max(0, 100-deviation/mean*100)
+}
+
+func maxOf(values []float64) float64 {
+	var acc float64
+	for _, value := range values {
+		acc = math.Max(acc, value)
+	}
+
+	return acc
+}
@@ -25,7 +25,8 @@ type myStopWatch struct {
 
 type mistakes struct {
 	mistakesAt     map[int]bool
-	rawMistakesCnt int // Should never be reduced
+	omittedAt      map[int]bool // Letters skipped over by space, also in mistakesAt
+	rawMistakesCnt int          // Should never be reduced
 }
 
 type StringStyle func(string) termenv.Style
@@ -35,17 +36,23 @@ type Styles struct {
 	toEnter      StringStyle
 	mistakes     StringStyle
 	cursor       StringStyle
+	pending      StringStyle
 	runningTimer StringStyle
 	stoppedTimer StringStyle
 	greener      StringStyle
 	faintGreen   StringStyle
+	heat         []StringStyle // From cool to hot
+	fingers      []StringStyle // Pinky, ring, middle and index finger, then thumb
+	nextKey      []StringStyle // Same as fingers, for the key to press next
 }
 
 type model struct {
 	state  State
 	styles Styles
+	keymap Keymap
 	width  int
 	height int
+	notice string // Outcome of the last catalog update, the one at startup included
 }
 
 type Results struct {
@@ -58,6 +65,16 @@ type Results struct {
 	time          time.Duration
 	wordList      string
 	wpmEachSecond []float64
+	formula       WpmFormula
+	charsPerWord  int
+	counts        TypingCounts
+	events        keystrokeLog
+	keepEvents    bool // Whether the events are persisted
+	words         []WordStat
+	metrics       SpeedMetrics
+	errorTypes    ErrorCounts
+	flags         []string // Reasons to leave the result out of comparisons
+	layout        string
 }
 
 type PersistentResults struct {
@@ -69,6 +86,7 @@ type ResultsIdentifier struct {
 	testType TestType
 	numeric  NumericSetting
 	words    WordListName
+	layout   string // Only results typed with this layout are compared, all of them when empty
 }
 
 type PersistentResultsNode struct {
@@ -78,6 +96,46 @@ type PersistentResultsNode struct {
 	RawWpm        int
 	Cpm           int
 	WpmEachSecond []float64
+	Formula       WpmFormula
+	CharsPerWord  int           `json:",omitempty"`
+	Seconds       float64       `json:",omitempty"`
+	Counts        *TypingCounts `json:",omitempty"` // Missing in results persisted before formulas were configurable
+	Keystrokes    string        `json:",omitempty"` // Encoded keystroke log, see keystrokeLog.encode
+
+	Consistency       float64      `json:",omitempty"`
+	BurstWpm          int          `json:",omitempty"`
+	CorrectedErrors   int          `json:",omitempty"`
+	UncorrectedErrors int          `json:",omitempty"`
+	ErrorsEachSecond  []float64    `json:",omitempty"`
+	ErrorTypes        *ErrorCounts `json:",omitempty"`
+	Flags             []string     `json:",omitempty"`
+	Layout            string       `json:",omitempty"` // Missing in results persisted before layouts were recorded, their layout is unknown
+	FinishedAt        *time.Time   `json:",omitempty"`
+}
+
+// Results with an unknown layout only count when all layouts are compared
+func (node PersistentResultsNode) layoutKnown() bool {
+	return node.Layout != ""
+}
+
+// Speed of the result as if it was calculated with the given formula
+func (node PersistentResultsNode) wpmWith(formula WpmFormula, charsPerWord int) (float64, bool) {
+	if node.Counts != nil && node.Seconds > 0 {
+		net, _ := formula.speed(*node.Counts, charsPerWord, node.Seconds/60)
+		return net, true
+	}
+
+	switch {
+	case node.Formula == formula:
+		return float64(node.Wpm), true
+	case node.Formula == standardFormula && formula == languageFormula && charsPerWord == 5:
+		return float64(node.Wpm), true
+	case formula == keystrokesFormula:
+		// Old results kept keystrokes per minute and accuracy, which is what's needed
+		return float64(node.Cpm) * node.Accuracy / 100, true
+	}
+
+	return 0, false
 }
 
 type WordListSelection struct {
@@ -154,6 +212,20 @@ type TestBase struct {
 	rawInputCnt   int // Should not be reduced
 	mistakes      mistakes
 	cursor        int
+	pending       []rune // Keys typed so far of an unfinished layout sequence
+	rtl           bool
+	charsPerWord  int
+	formula       WpmFormula
+	events        keystrokeLog
+	startedAt     time.Time      // When the timer or stopwatch has started
+	skipWords     bool           // Space mid-word jumps to the next word
+	overflow      map[int][]rune // Extra letters typed before the space at the key
+	pasted        bool
+	paused        bool
+	pausedAt      time.Time
+	pausedFor     time.Duration // Taken off event timestamps
+	gate          startGate
+	aligned       map[int]alignedWord // Error counts of finished words by index, see errorCounts
 }
 
 type TimerBasedTest struct {
@@ -168,6 +240,7 @@ type TimerBasedTestResults struct {
 	settings      TimerBasedTestSettings
 	wpmEachSecond []float64
 	results       Results
+	panel         ResultsPanel
 	mainMenu      MainMenu
 }
 
@@ -176,6 +249,7 @@ type WordCountBasedTest struct {
 	stopwatch myStopWatch
 	base      TestBase
 	completed bool
+	practice  []string // Words to practice instead of the selected word list
 	mainMenu  MainMenu
 }
 
@@ -184,6 +258,8 @@ type WordCountTestResults struct {
 	wpmEachSecond []float64
 	wordCnt       int
 	results       Results
+	panel         ResultsPanel
+	practice      []string
 	mainMenu      MainMenu
 }
 
@@ -200,6 +276,7 @@ type SentenceCountTestResults struct {
 	wpmEachSecond []float64
 	sentenceCnt   int
 	results       Results
+	panel         ResultsPanel
 	mainMenu      MainMenu
 }
 
@@ -224,10 +301,24 @@ type LayoutFile struct {
 	Name      string
 	Path      string
 	RemoteURI string
+	Checksum  string // SHA-256 from the catalog
+	isLocal   bool
+	local     *Layout // Loaded from the local config at startup
+	problem   string  // Why a local layout could not be loaded
 	synced    bool
+	syncing   bool // Being downloaded
 	syncOk    bool
 }
 
+// Layout declared in the local config, read from a JSON file or given inline
+type LocalLayout struct {
+	Name        string
+	Path        string
+	Mappings    map[string]string
+	AltMappings map[string]string
+	Sequences   map[string]string
+}
+
 func (layoutFile *LayoutFile) getLayout() (Layout, error) {
 	if !layoutFile.synced {
 		return Layout{}, errors.New("LayoutFile not synced")
@@ -237,8 +328,10 @@ func (layoutFile *LayoutFile) getLayout() (Layout, error) {
 }
 
 type Layout struct {
-	Name     string        `json:"name"`
-	Mappings map[rune]rune `json:"mappings"`
+	Name        string            `json:"name"`
+	Mappings    map[rune]rune     `json:"mappings"`
+	AltMappings map[rune]rune     `json:"altMappings,omitempty"` // Used when the key is pressed with Alt, which terminals send as a meta prefix. A real AltGr arrives already composed.
+	Sequences   map[string]string `json:"sequences,omitempty"`   // Dead keys, compose and transliteration, applied after mappings
 }
 
 type WordList struct {
@@ -246,9 +339,12 @@ type WordList struct {
 	Name      string
 	Path      string
 	RemoteURI string
+	Language  string
+	Checksum  string // SHA-256 from the catalog
 	isLocal   bool
 	Enabled   bool
 	synced    bool
+	syncing   bool // Being downloaded
 	syncOK    bool
 }
 
@@ -262,6 +358,7 @@ type EmbededWordList struct {
 	Name        string
 	IsSentences bool
 	Enabled     bool
+	Language    string
 }
 
 func (embeded *EmbededWordList) toggleEnabled() {
@@ -274,15 +371,76 @@ type Config struct {
 	WordLists          []WordList
 	LayoutFiles        []LayoutFile
 	Layout             Layout
+	WpmFormula         WpmFormula
+	DiscardKeystrokes  bool
+	SpaceSkipsWord     bool
+	PauseOnBlur        bool
+	Keyboard           KeyboardGeometry
+	CompareAllLayouts  bool
+	StartTrigger       StartTrigger
 	Version            int
+	languages          map[string]words.Language
+	keymap             Keymap
 }
 
 type LocalConfig struct {
-	Words []WordList
+	Words     []WordList
+	Layouts   []LocalLayout
+	Languages []words.Language
+	Keymap    KeymapConfig
+}
+
+func (cfg Config) language(code string) words.Language {
+	if language, ok := cfg.languages[code]; ok {
+		return language
+	}
+
+	language, _ := words.LookupLanguage(code)
+	return language
+}
+
+// Orders by language name, unknown languages go last
+func (cfg Config) languageLess(left string, right string) bool {
+	_, leftKnown := cfg.languages[left]
+	_, rightKnown := cfg.languages[right]
+	if leftKnown != rightKnown {
+		return leftKnown
+	}
+
+	return cfg.language(left).Name < cfg.language(right).Name
 }
 
 func (cfg Config) configTotalSelectionsCount() int {
-	return len(cfg.WordLists) + len(cfg.EmbededWordLists) + len(cfg.LayoutFiles)
+	return len(cfg.WordLists) + len(cfg.EmbededWordLists) + len(cfg.LayoutFiles) + len(configOptions)
+}
+
+func (cfg Config) wpmFormula() WpmFormula {
+	if cfg.WpmFormula == "" {
+		return languageFormula
+	}
+
+	return cfg.WpmFormula
+}
+
+func (cfg Config) keyboardGeometry() KeyboardGeometry {
+	if cfg.Keyboard == "" {
+		return noKeyboard
+	}
+
+	return cfg.Keyboard
+}
+
+// Layout whose results the current ones are compared with, empty for all of them
+func (cfg Config) comparedLayout() string {
+	if cfg.CompareAllLayouts {
+		return ""
+	}
+
+	return cfg.Layout.displayName()
+}
+
+func (cfg Config) keepKeystrokes() bool {
+	return !cfg.DiscardKeystrokes
 }
 
 type Toggleable interface {
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Index of the thumb in finger styles, the others go from the pinky (0) to the index finger (3)
+const thumbStyle = 4
+
+// Where a rune is typed with the layout
+type keyPress struct {
+	physical rune
+	shift    bool
+	alt      bool
+}
+
+func (layout Layout) keyPressFor(r rune) (keyPress, bool) {
+	if r == ' ' {
+		return keyPress{physical: ' '}, true
+	}
+
+	for _, row := range qwertyRows {
+		for _, physical := range row {
+			if layout.remap(physical, false) == r {
+				return keyPress{physical: physical}, true
+			}
+			if layout.remap(qwertyShifted(physical), false) == r {
+				return keyPress{physical: physical, shift: true}, true
+			}
+		}
+	}
+
+	for _, row := range qwertyRows {
+		for _, physical := range row {
+			if mapped, ok := layout.AltMappings[physical]; ok && mapped == r {
+				return keyPress{physical: physical, alt: true}, true
+			}
+			if mapped, ok := layout.AltMappings[qwertyShifted(physical)]; ok && mapped == r {
+				return keyPress{physical: physical, shift: true, alt: true}, true
+			}
+		}
+	}
+
+	// The shortest sequence producing the rune, its first key is the one to press now
+	var shortest []rune
+	for sequence, output := range layout.Sequences {
+		keys := []rune(sequence)
+		if output == string(r) && (shortest == nil || len(keys) < len(shortest) || len(keys) == len(shortest) && sequence < string(shortest)) {
+			shortest = keys
+		}
+	}
+	if shortest != nil && shortest[0] != r {
+		return layout.keyPressFor(shortest[0])
+	}
+
+	return keyPress{}, false
+}
+
+func fingerStyle(finger int) int {
+	return min(finger, 7-finger)
+}
+
+// Keyboard shown under the typing area with the key of the next letter highlighted.
+// Cells shrink when the terminal is narrow, nothing is shown when even that does not fit.
+func (m model) keyboardView(base TestBase, config Config, width int, room int) string {
+	if config.keyboardGeometry() == noKeyboard || room < len(qwertyRows)+2 {
+		return ""
+	}
+
+	var next keyPress
+	found := false
+	if at := len(base.inputBuffer); at < len(base.wordsToEnter) {
+		next, found = config.Layout.keyPressFor(base.wordsToEnter[at])
+	}
+
+	cell := func(label string, finger int, highlighted bool) string {
+		if highlighted {
+			return style(label, m.styles.nextKey[finger])
+		}
+
+		return style(label, m.styles.fingers[finger])
+	}
+	keys := func(format string) func(physical rune, label rune) string {
+		return func(physical rune, label rune) string {
+			return cell(fmt.Sprintf(format, label), fingerStyle(fingerOf(physical)), found && physical == next.physical)
+		}
+	}
+
+	keyWidth := 4
+	keyboard := renderKeyboard(config.Layout, config.keyboardGeometry(), keyWidth, keys(" %c "))
+	if lipgloss.Width(keyboard) > width {
+		keyWidth = 2
+		keyboard = renderKeyboard(config.Layout, config.keyboardGeometry(), keyWidth, keys("%c"))
+	}
+	keyboardWidth := lipgloss.Width(keyboard)
+	if keyboardWidth > width {
+		return ""
+	}
+
+	spaceBar := lipgloss.PlaceHorizontal(5*keyWidth-1, lipgloss.Center, "space")
+	modifiers := strings.Join([]string{
+		cell("shift", 0, found && next.shift),
+		cell(spaceBar, thumbStyle, found && next.physical == ' '),
+		cell("alt", thumbStyle, found && next.alt),
+	}, " ")
+	modifiers = lipgloss.PlaceHorizontal(keyboardWidth, lipgloss.Center, modifiers)
+
+	return "\n\n" + lipgloss.PlaceHorizontal(width, lipgloss.Center, keyboard+"\n"+modifiers)
+}
@@ -0,0 +1,107 @@
+package cmd
+
+// Setting shown in the config view, "e" cycles through its values
+type configOption struct {
+	name   string
+	values []string
+	get    func(cfg Config) string
+	set    func(cfg *Config, value string)
+}
+
+var configOptions = []configOption{
+	{
+		name:   "wpm formula",
+		values: formulaNames(wpmFormulas),
+		get:    func(cfg Config) string { return string(cfg.wpmFormula()) },
+		set:    func(cfg *Config, value string) { cfg.WpmFormula = WpmFormula(value) },
+	},
+	{
+		name:   "keystroke log",
+		values: []string{"on", "off"},
+		get:    func(cfg Config) string { return onOff(cfg.keepKeystrokes()) },
+		set:    func(cfg *Config, value string) { cfg.DiscardKeystrokes = value == "off" },
+	},
+	{
+		name:   "space skips word",
+		values: []string{"off", "on"},
+		get:    func(cfg Config) string { return onOff(cfg.SpaceSkipsWord) },
+		set:    func(cfg *Config, value string) { cfg.SpaceSkipsWord = value == "on" },
+	},
+	{
+		name:   "test start",
+		values: triggerNames(startTriggers),
+		get:    func(cfg Config) string { return string(newStartGate(cfg.StartTrigger).trigger) },
+		set:    func(cfg *Config, value string) { cfg.StartTrigger = StartTrigger(value) },
+	},
+	{
+		name:   "keyboard",
+		values: geometryNames(keyboardGeometries),
+		get:    func(cfg Config) string { return string(cfg.keyboardGeometry()) },
+		set:    func(cfg *Config, value string) { cfg.Keyboard = KeyboardGeometry(value) },
+	},
+	{
+		name:   "averages",
+		values: []string{"per layout", "all layouts"},
+		get: func(cfg Config) string {
+			if cfg.CompareAllLayouts {
+				return "all layouts"
+			}
+			return "per layout"
+		},
+		set: func(cfg *Config, value string) { cfg.CompareAllLayouts = value == "all layouts" },
+	},
+	{
+		name:   "pause on focus loss",
+		values: []string{"off", "on"},
+		get:    func(cfg Config) string { return onOff(cfg.PauseOnBlur) },
+		set:    func(cfg *Config, value string) { cfg.PauseOnBlur = value == "on" },
+	},
+}
+
+func (option configOption) cycle(cfg *Config) {
+	current := option.get(*cfg)
+	next := option.values[0]
+
+	for idx, value := range option.values {
+		if value == current {
+			next = option.values[(idx+1)%len(option.values)]
+		}
+	}
+
+	option.set(cfg, next)
+}
+
+func formulaNames(formulas []WpmFormula) []string {
+	var acc []string
+	for _, formula := range formulas {
+		acc = append(acc, string(formula))
+	}
+
+	return acc
+}
+
+func onOff(value bool) string {
+	if value {
+		return "on"
+	}
+
+	return "off"
+}
+
+func triggerNames(triggers []StartTrigger) []string {
+	var acc []string
+	for _, trigger := range triggers {
+		acc = append(acc, string(trigger))
+	}
+
+	return acc
+}
+
+func geometryNames(geometries []KeyboardGeometry) []string {
+	var acc []string
+	for _, geometry := range geometries {
+		acc = append(acc, string(geometry))
+	}
+
+	return acc
+}
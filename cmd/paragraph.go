@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/rivo/uniseg"
+)
+
+type glyphKind int
+
+const (
+	correctGlyph glyphKind = iota
+	mistakeGlyph
+	cursorGlyph
+	pendingGlyph
+	toEnterGlyph
+)
+
+// Single grapheme cluster as it is shown on the screen
+type glyph struct {
+	text  string
+	kind  glyphKind
+	width int // In terminal cells
+}
+
+func (g glyph) isSpace() bool {
+	return g.text == " "
+}
+
+func (g glyph) isCursor() bool {
+	return g.kind == cursorGlyph || g.kind == pendingGlyph
+}
+
+func (base *TestBase) paragraphView(lineLimit int, styles Styles) ([]string, int) {
+	var lines []string
+	cursorLine := 0
+
+	for idx, line := range wrapGlyphs(base.glyphs(), lineLimit) {
+		for _, g := range line {
+			if g.isCursor() {
+				cursorLine = idx
+			}
+		}
+		if base.rtl {
+			line = reorderRightToLeft(line)
+		}
+		lines = append(lines, renderGlyphs(line, styles))
+	}
+
+	if base.rtl {
+		lines = alignRight(lines)
+	}
+
+	return lines, cursorLine
+}
+
+func (base *TestBase) glyphs() []glyph {
+	var acc []glyph
+
+	// Paused text is hidden, so that it can't be read ahead
+	if base.paused {
+		at := 0
+		forEachGrapheme(base.wordsToEnter, func(cluster string, runeCnt int, width int) {
+			kind := toEnterGlyph
+			if at == len(base.inputBuffer) {
+				kind = cursorGlyph
+			}
+			if cluster != " " {
+				cluster = strings.Repeat("·", max(width, 1))
+			}
+			acc = append(acc, glyph{text: cluster, kind: kind, width: max(width, 1)})
+			at += runeCnt
+		})
+		return acc
+	}
+
+	// Mistyped letters show what should have been typed
+	shown := make([]rune, len(base.inputBuffer))
+	for idx, letter := range base.inputBuffer {
+		if base.mistakes.mistakesAt[idx] {
+			shown[idx] = base.wordsToEnter[idx]
+		} else {
+			shown[idx] = letter
+		}
+	}
+
+	at := 0
+	forEachGrapheme(shown, func(cluster string, runeCnt int, width int) {
+		acc = append(acc, base.overflowGlyphs(at)...)
+		kind := correctGlyph
+		for idx := at; idx < at+runeCnt; idx++ {
+			if base.mistakes.mistakesAt[idx] {
+				kind = mistakeGlyph
+			}
+		}
+		acc = append(acc, newGlyph(cluster, kind, width))
+		at += runeCnt
+	})
+
+	acc = append(acc, base.overflowGlyphs(len(base.inputBuffer))...)
+
+	rest := base.wordsToEnter[len(base.inputBuffer):]
+	cursorRunes := firstGraphemeLen(rest)
+
+	if len(base.pending) > 0 {
+		forEachGrapheme(base.pending, func(cluster string, runeCnt int, width int) {
+			acc = append(acc, newGlyph(cluster, pendingGlyph, width))
+		})
+	} else if cursorRunes > 0 {
+		forEachGrapheme(rest[:cursorRunes], func(cluster string, runeCnt int, width int) {
+			acc = append(acc, newGlyph(cluster, cursorGlyph, width))
+		})
+	}
+
+	forEachGrapheme(rest[cursorRunes:], func(cluster string, runeCnt int, width int) {
+		acc = append(acc, newGlyph(cluster, toEnterGlyph, width))
+	})
+
+	return acc
+}
+
+// Extra letters typed at the end of a word, in front of its space
+func (base *TestBase) overflowGlyphs(at int) []glyph {
+	var acc []glyph
+	forEachGrapheme(base.overflow[at], func(cluster string, runeCnt int, width int) {
+		acc = append(acc, newGlyph(cluster, mistakeGlyph, width))
+	})
+
+	return acc
+}
+
+func newGlyph(cluster string, kind glyphKind, width int) glyph {
+	// Lone combining marks would take no space, give them a dotted circle to sit on
+	if width == 0 {
+		cluster = "◌" + cluster
+		width = 1
+	}
+
+	return glyph{text: cluster, kind: kind, width: width}
+}
+
+func forEachGrapheme(runes []rune, fn func(cluster string, runeCnt int, width int)) {
+	graphemes := uniseg.NewGraphemes(string(runes))
+	for graphemes.Next() {
+		fn(graphemes.Str(), len(graphemes.Runes()), graphemes.Width())
+	}
+}
+
+func firstGraphemeLen(runes []rune) int {
+	graphemes := uniseg.NewGraphemes(string(runes))
+	if graphemes.Next() {
+		return len(graphemes.Runes())
+	}
+
+	return 0
+}
+
+// Breaks glyphs into lines after spaces. Words wider than a whole line are split.
+func wrapGlyphs(glyphs []glyph, lineLimit int) [][]glyph {
+	var lines [][]glyph
+	var line []glyph
+	lineWidth := 0
+
+	for start := 0; start < len(glyphs); {
+		end := start
+		wordWidth := 0
+		for end < len(glyphs) && !glyphs[end].isSpace() {
+			wordWidth += glyphs[end].width
+			end++
+		}
+
+		if lineWidth > 0 && lineWidth+wordWidth > lineLimit {
+			lines = append(lines, line)
+			line, lineWidth = nil, 0
+		}
+
+		for _, g := range glyphs[start:end] {
+			if lineWidth > 0 && lineWidth+g.width > lineLimit {
+				lines = append(lines, line)
+				line, lineWidth = nil, 0
+			}
+			line = append(line, g)
+			lineWidth += g.width
+		}
+
+		// Trailing spaces stay on the line they end, even if it gets a bit longer
+		for end < len(glyphs) && glyphs[end].isSpace() {
+			line = append(line, glyphs[end])
+			lineWidth += glyphs[end].width
+			end++
+		}
+
+		start = end
+	}
+
+	if len(line) > 0 {
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+func renderGlyphs(line []glyph, styles Styles) string {
+	var acc strings.Builder
+
+	for start := 0; start < len(line); {
+		end := start
+		var run strings.Builder
+		for end < len(line) && line[end].kind == line[start].kind {
+			run.WriteString(line[end].text)
+			end++
+		}
+
+		acc.WriteString(style(run.String(), line[start].kind.style(styles)))
+		start = end
+	}
+
+	return acc.String()
+}
+
+func (kind glyphKind) style(styles Styles) StringStyle {
+	switch kind {
+	case mistakeGlyph:
+		return styles.mistakes
+	case cursorGlyph:
+		return styles.cursor
+	case pendingGlyph:
+		return styles.pending
+	case toEnterGlyph:
+		return styles.toEnter
+	default:
+		return styles.correct
+	}
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func (base *TestBase) pause() {
+	base.paused = true
+	base.pausedAt = time.Now()
+}
+
+func (base *TestBase) resume() {
+	base.paused = false
+	base.pausedFor += time.Since(base.pausedAt)
+}
+
+// Paused time is left out of both the stopwatch and the keystroke log
+func (sw myStopWatch) togglePause(base *TestBase) tea.Cmd {
+	if base.paused {
+		base.resume()
+		return sw.stopwatch.Start()
+	}
+
+	base.pause()
+	return sw.stopwatch.Stop()
+}
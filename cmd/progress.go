@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/guptarohit/asciigraph"
+)
+
+// Days shown in the layout comparison chart
+const progressDays = 60
+
+var progressColors = []asciigraph.AnsiColor{asciigraph.Green, asciigraph.Yellow, asciigraph.Blue, asciigraph.Magenta, asciigraph.Cyan, asciigraph.Red}
+
+type LayoutProgress struct {
+	layouts []string
+	days    []string
+	daily   [][]float64 // Average speed of each layout on each day, a day without tests keeps the previous value
+	average []float64
+	tests   []int
+}
+
+// Results persisted before they had a timestamp or a layout can't be placed on the chart and are left out
+func layoutProgress(results PersistentResults, formula WpmFormula) LayoutProgress {
+	type bucket struct {
+		sum   float64
+		count int
+	}
+	byDay := map[string]map[string]*bucket{}
+	totals := map[string]*bucket{}
+
+	for _, numerics := range results.Results {
+		for _, lists := range numerics {
+			for _, nodes := range lists {
+				for _, node := range nodes {
+					if node.FinishedAt == nil || !node.layoutKnown() || len(node.Flags) > 0 {
+						continue
+					}
+					charsPerWord := node.CharsPerWord
+					if charsPerWord == 0 {
+						charsPerWord = 5
+					}
+					wpm, ok := node.wpmWith(formula, charsPerWord)
+					if !ok {
+						continue
+					}
+
+					day := node.FinishedAt.Local().Format("2006-01-02")
+					if byDay[day] == nil {
+						byDay[day] = map[string]*bucket{}
+					}
+					for _, buckets := range []map[string]*bucket{byDay[day], totals} {
+						if buckets[node.Layout] == nil {
+							buckets[node.Layout] = &bucket{}
+						}
+						buckets[node.Layout].sum += wpm
+						buckets[node.Layout].count++
+					}
+				}
+			}
+		}
+	}
+
+	var progress LayoutProgress
+	for day := range byDay {
+		progress.days = append(progress.days, day)
+	}
+	sort.Strings(progress.days)
+	if len(progress.days) > progressDays {
+		progress.days = progress.days[len(progress.days)-progressDays:]
+	}
+
+	for layout := range totals {
+		progress.layouts = append(progress.layouts, layout)
+	}
+	sort.Strings(progress.layouts)
+
+	for _, layout := range progress.layouts {
+		series := make([]float64, len(progress.days))
+		last := math.NaN()
+		for idx, day := range progress.days {
+			if bucket := byDay[day][layout]; bucket != nil {
+				last = bucket.sum / float64(bucket.count)
+			}
+			series[idx] = last
+		}
+		progress.daily = append(progress.daily, series)
+		progress.average = append(progress.average, totals[layout].sum/float64(totals[layout].count))
+		progress.tests = append(progress.tests, totals[layout].count)
+	}
+
+	return progress
+}
+
+func (m model) progressView(progress LayoutProgress, unit string) string {
+	if len(progress.layouts) == 0 {
+		return style("nothing to show yet, finish a few tests first", m.styles.toEnter)
+	}
+
+	var daily [][]float64
+	var colors []asciigraph.AnsiColor
+	var legends []string
+	for idx, series := range progress.daily {
+		// Layouts not used within the shown days have nothing to draw
+		if math.IsNaN(series[len(series)-1]) {
+			continue
+		}
+		// A single day would be a single column
+		if len(series) == 1 {
+			series = []float64{series[0], series[0]}
+		}
+		daily = append(daily, series)
+		colors = append(colors, progressColors[idx%len(progressColors)])
+		legends = append(legends, progress.layouts[idx])
+	}
+
+	var lines []string
+	for idx, layout := range progress.layouts {
+		lines = append(lines, fmt.Sprintf("  %-20s %6.1f %s over %d tests", layout, progress.average[idx], unit, progress.tests[idx]))
+	}
+	if len(daily) == 0 {
+		return strings.Join(lines, "\n") + "\n"
+	}
+
+	graph := asciigraph.PlotMany(
+		daily,
+		asciigraph.Precision(0),
+		asciigraph.Height(8),
+		asciigraph.SeriesColors(colors...),
+		asciigraph.SeriesLegends(legends...),
+		asciigraph.LabelColor(2),
+		asciigraph.Caption(fmt.Sprintf("daily average %s, %s to %s", unit, progress.days[0], progress.days[len(progress.days)-1])),
+		asciigraph.CaptionColor(2),
+	)
+
+	return lipgloss.NewStyle().Padding(1).Render(graph) + "\n" + strings.Join(lines, "\n") + "\n"
+}
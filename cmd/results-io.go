@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"slices"
+	"time"
 
 	"github.com/bloznelis/typioca/cmd/words"
 )
@@ -22,7 +24,6 @@ func PersistResults(results Results) PersistentResults {
 		persistentResults = defaultPersistentResults()
 	} else {
 		readResults(&persistentResults)
-		// XXX: Once needed, version check should happen here
 	}
 
 	persistentResults.addResults(results)
@@ -32,14 +33,27 @@ func PersistResults(results Results) PersistentResults {
 	return persistentResults
 }
 
+const currentResultsVersion = 2
+
 func defaultPersistentResults() PersistentResults {
 	return PersistentResults{
 		Results: AllPersistedResults{},
-		Version: 1,
+		Version: currentResultsVersion,
 	}
 }
 
 func ReadResults(i ResultsIdentifier) []PersistentResultsNode {
+	var persistentResults = LoadResults()
+	var res = make([]PersistentResultsNode, 0)
+	for _, node := range persistentResults.Results[i.testType][i.numeric][i.words] {
+		if i.layout == "" || node.layoutKnown() && node.Layout == i.layout {
+			res = append(res, node)
+		}
+	}
+	return res
+}
+
+func LoadResults() PersistentResults {
 	var resultsFile = getResultsPath()
 	var persistentResults PersistentResults
 
@@ -48,13 +62,30 @@ func ReadResults(i ResultsIdentifier) []PersistentResultsNode {
 		persistentResults = defaultPersistentResults()
 	} else {
 		readResults(&persistentResults)
-		// XXX: Once needed, version check should happen here
 	}
-	var res = persistentResults.Results[i.testType][i.numeric][i.words]
-	if res == nil {
-		return make([]PersistentResultsNode, 0)
+
+	return persistentResults
+}
+
+// Keystroke logs of every persisted result that has one
+func (p PersistentResults) keystrokeLogs() []keystrokeLog {
+	var acc []keystrokeLog
+	for _, numerics := range p.Results {
+		for _, lists := range numerics {
+			for _, nodes := range lists {
+				for _, node := range nodes {
+					if len(node.Flags) > 0 {
+						continue
+					}
+					if log, err := decodeKeystrokeLog(node.Keystrokes); err == nil {
+						acc = append(acc, log)
+					}
+				}
+			}
+		}
 	}
-	return res
+
+	return acc
 }
 
 func readResults(results *PersistentResults) {
@@ -67,10 +98,31 @@ func readResults(results *PersistentResults) {
 
 	decoder := json.NewDecoder(fh)
 	decoder.Decode(results)
+
+	migrateResults(results)
+}
+
+func migrateResults(results *PersistentResults) {
+	if results.Version < 2 {
+		// Everything before was measured with the standard formula
+		for _, numerics := range results.Results {
+			for _, wordLists := range numerics {
+				for _, nodes := range wordLists {
+					for idx := range nodes {
+						nodes[idx].Formula = standardFormula
+					}
+				}
+			}
+		}
+	}
+
+	// A newer binary may have written the file, don't let this one downgrade it
+	results.Version = max(results.Version, currentResultsVersion)
 }
 
 func (p *PersistentResults) addResults(results Results) {
 	var limit = 25 // XXX: Should be configurable eventually
+	var finishedAt = time.Now()
 
 	var node = PersistentResultsNode{
 		Wpm:           results.wpm,
@@ -79,6 +131,23 @@ func (p *PersistentResults) addResults(results Results) {
 		RawWpm:        results.rawWpm,
 		Cpm:           results.cpm,
 		WpmEachSecond: results.wpmEachSecond,
+		Formula:       results.formula,
+		CharsPerWord:  results.charsPerWord,
+		Seconds:       results.time.Seconds(),
+		Counts:        &results.counts,
+
+		Consistency:       results.metrics.consistency,
+		BurstWpm:          int(results.metrics.burst),
+		CorrectedErrors:   results.metrics.corrected,
+		UncorrectedErrors: results.metrics.uncorrected,
+		ErrorsEachSecond:  results.metrics.errorsEachSecond,
+		ErrorTypes:        &results.errorTypes,
+		Flags:             results.flags,
+		Layout:            results.layout,
+		FinishedAt:        &finishedAt,
+	}
+	if results.keepEvents {
+		node.Keystrokes = results.events.encode()
 	}
 	var i = results.identifier
 
@@ -92,11 +161,23 @@ func (p *PersistentResults) addResults(results Results) {
 
 	var nodes = append(p.Results[i.testType][i.numeric][i.words], node)
 
-	if len(nodes) > limit {
-		nodes = nodes[len(nodes)-limit:]
+	p.Results[i.testType][i.numeric][i.words] = lastOfEachLayout(nodes, limit)
+}
+
+// Keeps the last limit results of every layout, so practicing a new one doesn't push the old one out
+func lastOfEachLayout(nodes []PersistentResultsNode, limit int) []PersistentResultsNode {
+	var acc []PersistentResultsNode
+	kept := map[string]int{}
+	for idx := len(nodes) - 1; idx >= 0; idx-- {
+		layout := nodes[idx].Layout
+		if kept[layout] < limit {
+			kept[layout]++
+			acc = append(acc, nodes[idx])
+		}
 	}
+	slices.Reverse(acc)
 
-	p.Results[i.testType][i.numeric][i.words] = nodes
+	return acc
 }
 
 func writeResults(results PersistentResults) {
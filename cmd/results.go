@@ -5,17 +5,38 @@ import (
 	"strings"
 )
 
+type WpmFormula string
+
+const (
+	standardFormula     WpmFormula = "standard" // Net WPM with 5 characters per word
+	languageFormula     WpmFormula = "language" // Net WPM with characters per word of the list's language
+	correctCharsFormula WpmFormula = "correct"  // Correctly typed characters only
+	keystrokesFormula   WpmFormula = "kpm"      // Keystrokes per minute
+)
+
+var wpmFormulas = []WpmFormula{languageFormula, standardFormula, correctCharsFormula, keystrokesFormula}
+
+// Raw data of a test, enough to recompute its speed with any formula
+type TypingCounts struct {
+	Chars             int // Characters left in the input
+	CorrectChars      int
+	Keystrokes        int // Every key press, corrected ones included
+	KeystrokeMistakes int
+	UncorrectedErrors int
+}
+
 func (m TimerBasedTest) calculateResults() Results {
 	wordlist := m.settings.wordListSelections[m.settings.wordListCursor].name
 	identifier := ResultsIdentifier{
 		testType: "TimerBasedTest",
 		numeric:  int(m.timer.duration),
 		words:    wordlist,
+		layout:   m.mainMenu.config.comparedLayout(),
 	}
 
 	elapsedMinutes := m.timer.duration.Minutes()
 	wpm := m.base.calculateNormalizedWpm(elapsedMinutes)
-	deltaWpm := calculateAverageWpmDeltaPercentage(wpm, ReadResults(identifier))
+	deltaWpm := calculateAverageWpmDeltaPercentage(wpm, ReadResults(identifier), m.base.formula, m.base.charsPerWord)
 
 	return Results{
 		identifier:    identifier,
@@ -25,24 +46,42 @@ func (m TimerBasedTest) calculateResults() Results {
 		rawWpm:        int(m.base.calculateRawWpm(elapsedMinutes)),
 		cpm:           m.base.calculateCpm(elapsedMinutes),
 		time:          m.timer.duration,
+		flags:         m.base.flags(m.timer.duration),
 		wordList:      wordlist,
 		wpmEachSecond: m.base.wpmEachSecond,
+		formula:       m.base.formula,
+		charsPerWord:  m.base.charsPerWord,
+		counts:        m.base.counts(),
+		events:        m.base.events,
+		keepEvents:    m.mainMenu.config.keepKeystrokes(),
+		words:         m.base.wordStats(),
+		metrics:       m.base.metrics(),
+		errorTypes:    m.base.errorCounts(),
+		layout:        m.mainMenu.config.Layout.displayName(),
 	}
 }
 
 func (m WordCountBasedTest) calculateResults() Results {
 	count := m.settings.wordCountSelections[m.settings.wordCountCursor]
 	wordlist := m.settings.wordListSelections[m.settings.wordListCursor].name
+	flags := m.base.flags(m.stopwatch.stopwatch.Elapsed())
+	if m.practice != nil {
+		count = len(strings.Fields(string(m.base.wordsToEnter)))
+		wordlist = practiceWordList
+		// Deliberately hard words would skew averages, bigrams and progress
+		flags = append(flags, "practice run")
+	}
 
 	identifier := ResultsIdentifier{
 		testType: "WordCountBasedTest",
 		numeric:  count,
 		words:    wordlist,
+		layout:   m.mainMenu.config.comparedLayout(),
 	}
 
 	elapsedMinutes := m.stopwatch.stopwatch.Elapsed().Minutes()
 	wpm := m.base.calculateNormalizedWpm(elapsedMinutes)
-	deltaWpm := calculateAverageWpmDeltaPercentage(wpm, ReadResults(identifier))
+	deltaWpm := calculateAverageWpmDeltaPercentage(wpm, ReadResults(identifier), m.base.formula, m.base.charsPerWord)
 
 	return Results{
 		identifier:    identifier,
@@ -52,8 +91,18 @@ func (m WordCountBasedTest) calculateResults() Results {
 		rawWpm:        int(m.base.calculateRawWpm(elapsedMinutes)),
 		cpm:           m.base.calculateCpm(elapsedMinutes),
 		time:          m.stopwatch.stopwatch.Elapsed(),
+		flags:         flags,
 		wordList:      wordlist,
 		wpmEachSecond: m.base.wpmEachSecond,
+		formula:       m.base.formula,
+		charsPerWord:  m.base.charsPerWord,
+		counts:        m.base.counts(),
+		events:        m.base.events,
+		keepEvents:    m.mainMenu.config.keepKeystrokes(),
+		words:         m.base.wordStats(),
+		metrics:       m.base.metrics(),
+		errorTypes:    m.base.errorCounts(),
+		layout:        m.mainMenu.config.Layout.displayName(),
 	}
 }
 
@@ -65,11 +114,12 @@ func (m SentenceCountBasedTest) calculateResults() Results {
 		testType: "SentenceCountBasedTest",
 		numeric:  count,
 		words:    wordlist,
+		layout:   m.mainMenu.config.comparedLayout(),
 	}
 
 	elapsedMinutes := m.stopwatch.stopwatch.Elapsed().Minutes()
 	wpm := m.base.calculateNormalizedWpm(elapsedMinutes)
-	deltaWpm := calculateAverageWpmDeltaPercentage(wpm, ReadResults(identifier))
+	deltaWpm := calculateAverageWpmDeltaPercentage(wpm, ReadResults(identifier), m.base.formula, m.base.charsPerWord)
 
 	return Results{
 		identifier:    identifier,
@@ -79,54 +129,107 @@ func (m SentenceCountBasedTest) calculateResults() Results {
 		rawWpm:        int(m.base.calculateRawWpm(elapsedMinutes)),
 		cpm:           m.base.calculateCpm(elapsedMinutes),
 		time:          m.stopwatch.stopwatch.Elapsed(),
+		flags:         m.base.flags(m.stopwatch.stopwatch.Elapsed()),
 		wordList:      wordlist,
 		wpmEachSecond: m.base.wpmEachSecond,
+		formula:       m.base.formula,
+		charsPerWord:  m.base.charsPerWord,
+		counts:        m.base.counts(),
+		events:        m.base.events,
+		keepEvents:    m.mainMenu.config.keepKeystrokes(),
+		words:         m.base.wordStats(),
+		metrics:       m.base.metrics(),
+		errorTypes:    m.base.errorCounts(),
+		layout:        m.mainMenu.config.Layout.displayName(),
 	}
 }
 
-func calculateAverageWpmDeltaPercentage(wpm float64, previousResults []PersistentResultsNode) float64 {
-	previousAvg := calcPreviousResultsAvgWpm(previousResults)
+func calculateAverageWpmDeltaPercentage(wpm float64, previousResults []PersistentResultsNode, formula WpmFormula, charsPerWord int) float64 {
+	previousAvg := calcPreviousResultsAvgWpm(previousResults, formula, charsPerWord)
 
 	return ((wpm - previousAvg) / math.Max(1.0, previousAvg)) * 100
 }
 
-func calcPreviousResultsAvgWpm(previousResults []PersistentResultsNode) float64 {
-	if len(previousResults) == 0 {
-		return 0
-	}
-	var sum int
+// Flagged results and results which can't be recomputed with the given formula are left out
+func calcPreviousResultsAvgWpm(previousResults []PersistentResultsNode, formula WpmFormula, charsPerWord int) float64 {
+	var sum float64
+	var cnt int
 	for _, v := range previousResults {
-		sum += v.Wpm
+		if len(v.Flags) > 0 {
+			continue
+		}
+		if wpm, ok := v.wpmWith(formula, charsPerWord); ok {
+			sum += wpm
+			cnt++
+		}
+	}
+
+	if cnt == 0 {
+		return 0
 	}
 
-	return float64(sum) / float64(len(previousResults))
+	return sum / float64(cnt)
+}
+
+func (base TestBase) counts() TypingCounts {
+	uncorrected := base.errorCounts().total()
+
+	return TypingCounts{
+		Chars:             len(base.typedRange(0, len(base.inputBuffer))),
+		CorrectChars:      max(0, len(base.inputBuffer)-uncorrected),
+		Keystrokes:        base.rawInputCnt,
+		KeystrokeMistakes: base.mistakes.rawMistakesCnt,
+		UncorrectedErrors: uncorrected,
+	}
 }
 
 func (base TestBase) calculateNormalizedWpm(elapsedMinutes float64) float64 {
-	return base.calculateWpm(len(base.inputBuffer)/5, elapsedMinutes)
+	net, _ := base.formula.speed(base.counts(), base.charsPerWord, elapsedMinutes)
+	return net
 }
 
 func (base TestBase) calculateRawWpm(elapsedMinutes float64) float64 {
-	return base.calculateWpm(len(strings.Split(string(base.inputBuffer), " ")), elapsedMinutes)
+	_, gross := base.formula.speed(base.counts(), base.charsPerWord, elapsedMinutes)
+	return gross
 }
 
-func (base TestBase) calculateWpm(wordCnt int, elapsedMinutes float64) float64 {
+// Returns net and gross speed
+func (formula WpmFormula) speed(counts TypingCounts, charsPerWord int, elapsedMinutes float64) (float64, float64) {
 	if elapsedMinutes == 0 {
-		return 0
-	} else {
-		grossWpm := float64(wordCnt) / elapsedMinutes
-		netWpm := grossWpm - float64(len(base.mistakes.mistakesAt))/elapsedMinutes
+		return 0, 0
+	}
 
-		return math.Max(0, netWpm)
+	switch formula {
+	case correctCharsFormula:
+		return float64(counts.CorrectChars) / 5 / elapsedMinutes, float64(counts.Chars) / 5 / elapsedMinutes
+	case keystrokesFormula:
+		return math.Max(0, float64(counts.Keystrokes-counts.KeystrokeMistakes)/elapsedMinutes), float64(counts.Keystrokes) / elapsedMinutes
+	case standardFormula:
+		charsPerWord = 5
 	}
+
+	grossWpm := float64(counts.Chars) / float64(charsPerWord) / elapsedMinutes
+	netWpm := grossWpm - float64(counts.UncorrectedErrors)/elapsedMinutes
+
+	return math.Max(0, netWpm), grossWpm
+}
+
+func (formula WpmFormula) unit() string {
+	if formula == keystrokesFormula {
+		return "kpm"
+	}
+
+	return "wpm"
 }
 
 func (base TestBase) calculateCpm(elapsedMinutes float64) int {
 	return int(float64(base.rawInputCnt) / elapsedMinutes)
 }
 
+// Mistakes left in the text are counted by alignment, not by every shifted letter after them
 func (base TestBase) calculateAccuracy() float64 {
-	mistakesRate := float64(base.mistakes.rawMistakesCnt*100) / float64(base.rawInputCnt)
+	mistakesCnt := max(0, base.mistakes.rawMistakesCnt-base.positionalMistakes()) + base.errorCounts().total()
+	mistakesRate := float64(mistakesCnt*100) / float64(base.rawInputCnt)
 	accuracy := 100 - mistakesRate
-	return accuracy
+	return math.Max(0, accuracy)
 }
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestAccuracyWithSkippedWords(t *testing.T) {
+	tests := []struct {
+		name    string
+		toEnter string
+		typed   string // Spaces skip the rest of a started word
+	}{
+		{name: "long word skipped", toEnter: "t counterrevolutionaries go", typed: "t co go"},
+		{name: "every word skipped", toEnter: "internationalization electroencephalography", typed: "i e"},
+		{name: "skip after a mistake", toEnter: "the incomprehensibilities", typed: "tha i"},
+		{name: "no skips", toEnter: "the cat", typed: "the cat"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := TestBase{
+				wordsToEnter: []rune(tt.toEnter),
+				mistakes:     mistakes{mistakesAt: map[int]bool{}, omittedAt: map[int]bool{}},
+				skipWords:    true,
+				overflow:     map[int][]rune{},
+				aligned:      map[int]alignedWord{},
+				gate:         newStartGate(firstKeyTrigger),
+			}
+			for _, r := range tt.typed {
+				if r == ' ' {
+					handleSpace(&base, Layout{})
+				} else {
+					handleRunes(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}}, &base, Layout{})
+				}
+			}
+
+			if accuracy := base.calculateAccuracy(); accuracy < 0 || accuracy > 100 {
+				t.Errorf("accuracy %.1f%%, want it within 0..100", accuracy)
+			}
+			if base.mistakes.rawMistakesCnt > base.rawInputCnt {
+				t.Errorf("%d keystroke mistakes out of %d keystrokes", base.mistakes.rawMistakesCnt, base.rawInputCnt)
+			}
+			counts := TypingCounts{Keystrokes: base.rawInputCnt, KeystrokeMistakes: base.mistakes.rawMistakesCnt}
+			if net, _ := keystrokesFormula.speed(counts, 5, 1); net < 0 {
+				t.Errorf("net speed %.1f kpm, want it at least 0", net)
+			}
+		})
+	}
+}
+
+func TestSpeedNeverNegative(t *testing.T) {
+	counts := TypingCounts{Keystrokes: 5, KeystrokeMistakes: 18, Chars: 5, UncorrectedErrors: 18}
+	for _, formula := range []WpmFormula{correctCharsFormula, keystrokesFormula, standardFormula} {
+		if net, _ := formula.speed(counts, 5, 1); net < 0 {
+			t.Errorf("%v gives a net speed of %.1f", formula, net)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type StartTrigger string
+
+const (
+	firstKeyTrigger  StartTrigger = "first key" // Timing starts with the first letter
+	countdownTrigger StartTrigger = "countdown"
+	startKeyTrigger  StartTrigger = "start key"
+)
+
+var startTriggers = []StartTrigger{firstKeyTrigger, countdownTrigger, startKeyTrigger}
+
+const countdownSeconds = 3
+
+// Tests of different sessions may count down at the same time
+var countdownIds atomic.Int64
+
+type countdownTickMsg struct {
+	id int64
+}
+
+// Keeps the input locked until the test starts
+type startGate struct {
+	trigger   StartTrigger
+	open      bool
+	countdown int // Seconds left
+	id        int64
+	ticking   bool // A tick for the current second is on its way
+}
+
+func newStartGate(trigger StartTrigger) startGate {
+	switch trigger {
+	case countdownTrigger:
+		return startGate{trigger: trigger, countdown: countdownSeconds, id: countdownIds.Add(1)}
+	case startKeyTrigger:
+		return startGate{trigger: trigger}
+	default:
+		return startGate{trigger: firstKeyTrigger, open: true}
+	}
+}
+
+// Returns true when the tick has opened the gate
+func (gate *startGate) tick(msg countdownTickMsg) bool {
+	if msg.id != gate.id || gate.open {
+		return false
+	}
+
+	gate.ticking = false
+	gate.countdown--
+	gate.open = gate.countdown <= 0
+
+	return gate.open
+}
+
+// Returns true when the key has opened the gate
+func (gate *startGate) startKey() bool {
+	if gate.trigger != startKeyTrigger || gate.open {
+		return false
+	}
+
+	gate.open = true
+	return true
+}
+
+func (gate *startGate) nextTick() tea.Cmd {
+	if gate.open || gate.trigger != countdownTrigger || gate.ticking {
+		return nil
+	}
+
+	gate.ticking = true
+	id := gate.id
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return countdownTickMsg{id: id}
+	})
+}
+
+// Shown instead of the timer before the test starts
+func (gate startGate) view(keymap Keymap) string {
+	if gate.trigger == countdownTrigger {
+		return strconv.Itoa(gate.countdown)
+	}
+
+	return "press " + keymap.key(startAction) + " to start"
+}
+
+func (base TestBase) locked() bool {
+	return base.paused || !base.gate.open
+}
+
+func (test *TimerBasedTest) start() tea.Cmd {
+	if test.timer.isRunning {
+		return nil
+	}
+
+	test.timer.isRunning = true
+	test.base.startedAt = time.Now()
+	return test.timer.timer.Init()
+}
+
+func (test *WordCountBasedTest) start() tea.Cmd {
+	if test.stopwatch.isRunning {
+		return nil
+	}
+
+	test.stopwatch.isRunning = true
+	test.base.startedAt = time.Now()
+	return test.stopwatch.stopwatch.Init()
+}
+
+func (test *SentenceCountBasedTest) start() tea.Cmd {
+	if test.stopwatch.isRunning {
+		return nil
+	}
+
+	test.stopwatch.isRunning = true
+	test.base.startedAt = time.Now()
+	return test.stopwatch.stopwatch.Init()
+}
+
+// Countdowns tick on their own, whatever state transition has started them
+func (m model) scheduleCountdown(cmd tea.Cmd) (tea.Model, tea.Cmd) {
+	var next tea.Cmd
+	switch state := m.state.(type) {
+	case TimerBasedTest:
+		next = state.base.gate.nextTick()
+		m.state = state
+	case WordCountBasedTest:
+		next = state.base.gate.nextTick()
+		m.state = state
+	case SentenceCountBasedTest:
+		next = state.base.gate.nextTick()
+		m.state = state
+	}
+
+	return m, tea.Batch(cmd, next)
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type StatsViewSelection struct{}
+
+func (s StatsViewSelection) Enabled() bool {
+	return true
+}
+
+func (selection StatsViewSelection) show(styles Styles) string {
+	return "Stats "
+}
+
+type statsTab struct {
+	name string
+	view func(m model, stats StatsView, rowLimit int) string
+}
+
+var statsTabs = []statsTab{
+	{name: "slowest bigrams", view: bigramsTab(slowestBigrams)},
+	{name: "error-prone bigrams", view: bigramsTab(errorProneBigrams)},
+	{name: "layout", view: layoutTab},
+	{name: "layout progress", view: func(m model, stats StatsView, rowLimit int) string {
+		return m.progressView(stats.progress, stats.mainMenu.config.wpmFormula().unit())
+	}},
+}
+
+type StatsView struct {
+	tab      int
+	bigrams  []BigramStat
+	analysis LayoutAnalysis // Of the current layout over the typed history
+	progress LayoutProgress
+	mainMenu MainMenu
+}
+
+func initStatsView(menu MainMenu) StatsView {
+	results := LoadResults()
+	logs := results.keystrokeLogs()
+
+	return StatsView{
+		bigrams:  bigramStats(logs),
+		analysis: analyzeLayout(menu.config.Layout, historyText(logs)),
+		progress: layoutProgress(results, menu.config.wpmFormula()),
+		mainMenu: menu,
+	}
+}
+
+func (selection StatsViewSelection) handleInput(msg tea.Msg, menu MainMenu) State {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch menu.config.keymap.action(menuContext, msg) {
+		case selectAction:
+			return initStatsView(menu)
+		case upAction:
+			if menu.cursor > 0 {
+				menu.cursor--
+			}
+		case downAction:
+			if menu.cursor < len(menu.selections)-1 {
+				menu.cursor++
+			} else {
+				menu.cursor = 0
+			}
+		}
+	}
+
+	return menu
+}
+
+func (stats StatsView) handleInput(msg tea.Msg, state State) State {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch stats.mainMenu.config.keymap.action(statsContext, msg) {
+		case rightAction:
+			stats.tab = (stats.tab + 1) % len(statsTabs)
+			state = stats
+		case leftAction:
+			stats.tab = (stats.tab + len(statsTabs) - 1) % len(statsTabs)
+			state = stats
+		case menuAction:
+			state = stats.mainMenu
+		}
+	}
+
+	return state
+}
+
+func (m model) statsView(stats StatsView, rowLimit int) string {
+	var tabs []string
+	for idx, tab := range statsTabs {
+		if idx == stats.tab {
+			tabs = append(tabs, "["+style(tab.name, m.styles.runningTimer)+"]")
+		} else {
+			tabs = append(tabs, "["+style(tab.name, m.styles.greener)+"]")
+		}
+	}
+
+	view := "Stats\n\n" + strings.Join(tabs, " ") + "\n\n"
+
+	return view + statsTabs[stats.tab].view(m, stats, rowLimit)
+}
+
+func bigramsTab(rowsFn func(stats []BigramStat) []BigramStat) func(m model, stats StatsView, rowLimit int) string {
+	return func(m model, stats StatsView, rowLimit int) string {
+		rows := rowsFn(stats.bigrams)
+		if len(rows) == 0 {
+			return style("nothing to show yet, finish a few tests first", m.styles.toEnter)
+		}
+
+		view := fmt.Sprintf("  %-8s %8s %8s %8s\n", "bigram", "avg ms", "count", "errors")
+		for _, stat := range rows[:min(len(rows), rowLimit)] {
+			view += fmt.Sprintf("  %-8s %8.0f %8d %7.1f%%\n", showBigram(stat.Bigram), stat.AverageMs, stat.Count, stat.errorRate()*100)
+		}
+
+		return view
+	}
+}
+
+func layoutTab(m model, stats StatsView, rowLimit int) string {
+	if stats.analysis.Letters == 0 {
+		return style("nothing to show yet, finish a few tests first", m.styles.toEnter)
+	}
+
+	lines := analysisTable([]LayoutAnalysis{stats.analysis})
+	var view string
+	for _, line := range lines[:min(len(lines), rowLimit)] {
+		view += "  " + line + "\n"
+	}
+
+	return view + "\n" + style("over the typed history, compare layouts with typioca layout analyze", m.styles.toEnter)
+}
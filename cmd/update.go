@@ -11,6 +11,11 @@ import (
 )
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	updated, cmd := m.update(msg)
+	return updated.(model).scheduleCountdown(cmd)
+}
+
+func (m model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var commands []tea.Cmd
 
 	switch msg := msg.(type) {
@@ -27,23 +32,57 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	// Is it a key press?
 	case tea.KeyMsg:
-
-		// Cool, what was the actual key pressed?
-		switch msg.String() {
-
-		// These keys should exit the program.
-		case "ctrl+c", "esc":
+		// Quit is bound the same way in every context
+		if m.keymap.action(menuContext, msg) == quitAction {
 			return m, tea.Quit
 		}
+
+	case catalogRefreshedMsg:
+		m.notice = "catalog updated"
+		if msg.err != nil {
+			m.notice = "catalog update failed: " + msg.err.Error()
+		}
+		return m.withConfigView(func(configView ConfigView) ConfigView {
+			return configView.catalogRefreshed(msg)
+		}), nil
+
+	case downloadedMsg:
+		return m.withConfigView(func(configView ConfigView) ConfigView {
+			return configView.downloaded(msg)
+		}), nil
 	}
 
 	switch state := m.state.(type) {
 	case MainMenu:
 		m.state = state.selections[state.cursor].handleInput(msg, state)
 		WriteConfig(state.config)
-		return m.quitOn(msg, "ctrl+q")
+		if state.config.keymap.action(menuContext, msg) == menuAction {
+			return m, tea.Quit
+		}
+		return m, nil
 
 	case ConfigView:
+		switch action := state.config.keymap.action(configContext, msg); action {
+		case catalogAction:
+			return m.refreshCatalog()
+		case syncAction, toggleAction:
+			if configView, cmd := state.download(action == toggleAction); cmd != nil {
+				m.state = configView
+				return m, cmd
+			}
+		}
+		m.state = state.handleInput(msg, state)
+		return m, nil
+
+	case StatsView:
+		m.state = state.handleInput(msg, state)
+		return m, nil
+
+	case CalibrationWizard:
+		m.state = state.handleInput(msg, state)
+		return m, nil
+
+	case LayoutEditor:
 		m.state = state.handleInput(msg, state)
 		return m, nil
 
@@ -62,6 +101,19 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case TimerBasedTest:
 		switch msg := msg.(type) {
 
+		case tea.BlurMsg:
+			// A timer can't be paused without changing what the test measures
+			if state.mainMenu.config.PauseOnBlur && state.timer.isRunning {
+				m.state = initTimerBasedTest(state.settings, state.mainMenu)
+				return m, nil
+			}
+
+		case countdownTickMsg:
+			if state.base.gate.tick(msg) {
+				commands = append(commands, state.start())
+			}
+			m.state = state
+
 		case timer.TickMsg:
 			timerUpdate, cmdUpdate := state.timer.timer.Update(msg)
 			state.timer.timer = timerUpdate
@@ -80,48 +132,52 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 				var results = state.calculateResults()
 
-				PersistResults(results)
+				persisted := PersistResults(results)
 
 				m.state = TimerBasedTestResults{
 					settings:      state.settings,
 					wpmEachSecond: state.base.wpmEachSecond,
 					results:       results,
+					panel:         newResultsPanel(results, persisted),
 					mainMenu:      state.mainMenu,
 				}
 			}
 
 		case tea.KeyMsg:
-			switch msg.String() {
-			case "enter", "tab":
-
-			case "ctrl+q":
+			switch state.mainMenu.config.keymap.action(testContext, msg) {
+			case menuAction:
 				m.state = state.mainMenu
 				return m, nil
 
-			case "ctrl+r":
+			case restartAction:
 				m.state = initTimerBasedTest(state.settings, state.mainMenu)
 				return m, nil
 
-			case "backspace", "ctrl+h":
-				handleBackspace(&state.base)
+			case startAction:
+				if state.base.gate.startKey() {
+					commands = append(commands, state.start())
+				}
 				m.state = state
 
-			case "ctrl+w":
-				handleCtrlW(&state.base)
+			case deleteLetterAction:
+				handleBackspace(&state.base)
 				m.state = state
 
-			case " ":
-				handleSpace(&state.base)
+			case deleteWordAction:
+				handleCtrlW(&state.base)
 				m.state = state
 
 			default:
 				switch msg.Type {
+				case tea.KeySpace:
+					handleSpace(&state.base, state.mainMenu.config.Layout)
+					m.state = state
 				case tea.KeyRunes:
-					if !state.timer.isRunning {
-						commands = append(commands, state.timer.timer.Init())
-						state.timer.isRunning = true
+					if state.base.locked() {
+						break
 					}
-					handleRunes(msg, &state.base, state.mainMenu.config.Layout.Mappings)
+					commands = append(commands, state.start())
+					handleRunes(msg, &state.base, state.mainMenu.config.Layout)
 					m.state = state
 				}
 			}
@@ -137,12 +193,27 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 			m.state = state
 
+		case countdownTickMsg:
+			if state.base.gate.tick(msg) {
+				commands = append(commands, state.start())
+			}
+			m.state = state
+
+		case tea.BlurMsg:
+			if state.mainMenu.config.PauseOnBlur && state.stopwatch.isRunning && !state.base.paused {
+				commands = append(commands, state.stopwatch.togglePause(&state.base))
+				m.state = state
+			}
+
 		case stopwatch.TickMsg:
 			stopwatchUpdate, cmdUpdate := state.stopwatch.stopwatch.Update(msg)
 			state.stopwatch.stopwatch = stopwatchUpdate
 			commands = append(commands, cmdUpdate)
 
 			elapsedMinutes := state.stopwatch.stopwatch.Elapsed().Minutes()
+			if state.base.paused {
+				elapsedMinutes = 0
+			}
 
 			if elapsedMinutes != 0 {
 				state.base.wpmEachSecond = append(state.base.wpmEachSecond, state.base.calculateNormalizedWpm(elapsedMinutes))
@@ -151,37 +222,50 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.state = state
 
 		case tea.KeyMsg:
-			switch msg.String() {
-			case "enter", "tab":
-
-			case "ctrl+q":
+			switch state.mainMenu.config.keymap.action(testContext, msg) {
+			case menuAction:
 				m.state = state.mainMenu
 				return m, nil
 
-			case "ctrl+r":
-				m.state = initWordCountBasedTest(state.settings, state.mainMenu)
+			case restartAction:
+				if state.practice != nil {
+					m.state = initPracticeTest(state.practice, state.settings, state.mainMenu)
+				} else {
+					m.state = initWordCountBasedTest(state.settings, state.mainMenu)
+				}
 				return m, nil
 
-			case "backspace", "ctrl+h":
-				handleBackspace(&state.base)
+			case pauseAction:
+				if state.stopwatch.isRunning {
+					commands = append(commands, state.stopwatch.togglePause(&state.base))
+					m.state = state
+				}
+
+			case startAction:
+				if state.base.gate.startKey() {
+					commands = append(commands, state.start())
+				}
 				m.state = state
 
-			case "ctrl+w":
-				handleCtrlW(&state.base)
+			case deleteLetterAction:
+				handleBackspace(&state.base)
 				m.state = state
 
-			case " ":
-				handleSpace(&state.base)
+			case deleteWordAction:
+				handleCtrlW(&state.base)
 				m.state = state
 
 			default:
 				switch msg.Type {
+				case tea.KeySpace:
+					handleSpace(&state.base, state.mainMenu.config.Layout)
+					m.state = state
 				case tea.KeyRunes:
-					if !state.stopwatch.isRunning {
-						commands = append(commands, state.stopwatch.stopwatch.Init())
-						state.stopwatch.isRunning = true
+					if state.base.locked() {
+						break
 					}
-					handleRunes(msg, &state.base, state.mainMenu.config.Layout.Mappings)
+					commands = append(commands, state.start())
+					handleRunes(msg, &state.base, state.mainMenu.config.Layout)
 					m.state = state
 
 				}
@@ -194,13 +278,15 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 			var results = state.calculateResults()
 
-			PersistResults(results)
+			persisted := PersistResults(results)
 
 			m.state = WordCountTestResults{
 				settings:      state.settings,
 				wpmEachSecond: state.base.wpmEachSecond,
-				wordCnt:       state.settings.wordCountSelections[state.settings.wordCountCursor],
+				wordCnt:       results.identifier.numeric,
 				results:       results,
+				panel:         newResultsPanel(results, persisted),
+				practice:      state.practice,
 				mainMenu:      state.mainMenu,
 			}
 		}
@@ -215,12 +301,27 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 			m.state = state
 
+		case countdownTickMsg:
+			if state.base.gate.tick(msg) {
+				commands = append(commands, state.start())
+			}
+			m.state = state
+
+		case tea.BlurMsg:
+			if state.mainMenu.config.PauseOnBlur && state.stopwatch.isRunning && !state.base.paused {
+				commands = append(commands, state.stopwatch.togglePause(&state.base))
+				m.state = state
+			}
+
 		case stopwatch.TickMsg:
 			stopwatchUpdate, cmdUpdate := state.stopwatch.stopwatch.Update(msg)
 			state.stopwatch.stopwatch = stopwatchUpdate
 			commands = append(commands, cmdUpdate)
 
 			elapsedMinutes := state.stopwatch.stopwatch.Elapsed().Minutes()
+			if state.base.paused {
+				elapsedMinutes = 0
+			}
 			if elapsedMinutes != 0 {
 				state.base.wpmEachSecond = append(state.base.wpmEachSecond, state.base.calculateNormalizedWpm(elapsedMinutes))
 			}
@@ -228,37 +329,46 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.state = state
 
 		case tea.KeyMsg:
-			switch msg.String() {
-			case "enter", "tab":
-
-			case "ctrl+q":
+			switch state.mainMenu.config.keymap.action(testContext, msg) {
+			case menuAction:
 				m.state = state.mainMenu
 				return m, nil
 
-			case "ctrl+r":
+			case restartAction:
 				m.state = initSentenceCountBasedTest(state.settings, state.mainMenu)
 				return m, nil
 
-			case "backspace", "ctrl+h":
-				handleBackspace(&state.base)
+			case pauseAction:
+				if state.stopwatch.isRunning {
+					commands = append(commands, state.stopwatch.togglePause(&state.base))
+					m.state = state
+				}
+
+			case startAction:
+				if state.base.gate.startKey() {
+					commands = append(commands, state.start())
+				}
 				m.state = state
 
-			case "ctrl+w":
-				handleCtrlW(&state.base)
+			case deleteLetterAction:
+				handleBackspace(&state.base)
 				m.state = state
 
-			case " ":
-				handleSpace(&state.base)
+			case deleteWordAction:
+				handleCtrlW(&state.base)
 				m.state = state
 
 			default:
 				switch msg.Type {
+				case tea.KeySpace:
+					handleSpace(&state.base, state.mainMenu.config.Layout)
+					m.state = state
 				case tea.KeyRunes:
-					if !state.stopwatch.isRunning {
-						commands = append(commands, state.stopwatch.stopwatch.Init())
-						state.stopwatch.isRunning = true
+					if state.base.locked() {
+						break
 					}
-					handleRunes(msg, &state.base, state.mainMenu.config.Layout.Mappings)
+					commands = append(commands, state.start())
+					handleRunes(msg, &state.base, state.mainMenu.config.Layout)
 					m.state = state
 				}
 			}
@@ -270,13 +380,14 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 			var results = state.calculateResults()
 
-			PersistResults(results)
+			persisted := PersistResults(results)
 
 			m.state = SentenceCountTestResults{
 				settings:      state.settings,
 				wpmEachSecond: state.base.wpmEachSecond,
 				sentenceCnt:   state.settings.sentenceCountSelections[state.settings.sentenceCountCursor],
 				results:       results,
+				panel:         newResultsPanel(results, persisted),
 				mainMenu:      state.mainMenu,
 			}
 		}
@@ -287,39 +398,27 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(commands...)
 }
 
-func (m model) quitOn(msg tea.Msg, strokes ...string) (tea.Model, tea.Cmd) {
-	switch msg := msg.(type) {
-	case tea.KeyMsg:
-		for _, elem := range strokes {
-			if elem == msg.String() {
-				return m, tea.Quit
-			}
-		}
-	}
-	return m, nil
-}
-
 func (settings TimerBasedTestSettings) handleInput(msg tea.Msg, menu MainMenu) State {
 	cursorToSave := menu.cursor
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "enter":
+		switch menu.config.keymap.action(menuContext, msg) {
+		case selectAction:
 			if settings.enabled {
 				return initTimerBasedTest(settings, menu)
 			}
-		case "left", "h":
+		case leftAction:
 			if settings.cursor > 0 {
 				settings.cursor--
 			}
-		case "right", "l", "tab":
+		case rightAction:
 			if settings.cursor < 2 {
 				settings.cursor++
 			} else {
 				settings.cursor = 0
 			}
-		case "up", "k":
+		case upAction:
 			switch settings.cursor {
 			case 0:
 				if menu.cursor > 0 {
@@ -340,7 +439,7 @@ func (settings TimerBasedTestSettings) handleInput(msg tea.Msg, menu MainMenu) S
 					settings.wordListCursor = len(settings.wordListSelections) - 1
 				}
 			}
-		case "down", "j":
+		case downAction:
 			switch settings.cursor {
 			case 0:
 				if menu.cursor < len(menu.selections)-1 {
@@ -374,22 +473,22 @@ func (settings WordCountBasedTestSettings) handleInput(msg tea.Msg, menu MainMen
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "enter":
+		switch menu.config.keymap.action(menuContext, msg) {
+		case selectAction:
 			if settings.enabled {
 				return initWordCountBasedTest(settings, menu)
 			}
-		case "left", "h":
+		case leftAction:
 			if settings.cursor > 0 {
 				settings.cursor--
 			}
-		case "right", "l", "tab":
+		case rightAction:
 			if settings.cursor < 2 {
 				settings.cursor++
 			} else {
 				settings.cursor = 0
 			}
-		case "up", "k":
+		case upAction:
 			switch settings.cursor {
 			case 0:
 				if menu.cursor > 0 {
@@ -408,7 +507,7 @@ func (settings WordCountBasedTestSettings) handleInput(msg tea.Msg, menu MainMen
 					settings.wordListCursor = len(settings.wordListSelections) - 1
 				}
 			}
-		case "down", "j":
+		case downAction:
 			switch settings.cursor {
 			case 0:
 				if menu.cursor < len(menu.selections)-1 {
@@ -440,14 +539,14 @@ func (settings WordCountBasedTestSettings) handleInput(msg tea.Msg, menu MainMen
 func (selection ConfigViewSelection) handleInput(msg tea.Msg, menu MainMenu) State {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "enter":
+		switch menu.config.keymap.action(menuContext, msg) {
+		case selectAction:
 			return initConfigView(menu.config, menu)
-		case "up", "k":
+		case upAction:
 			if menu.cursor > 0 {
 				menu.cursor--
 			}
-		case "down", "j":
+		case downAction:
 			if menu.cursor < len(menu.selections)-1 {
 				menu.cursor++
 			} else {
@@ -465,22 +564,22 @@ func (settings SentenceCountBasedTestSettings) handleInput(msg tea.Msg, menu Mai
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "enter":
+		switch menu.config.keymap.action(menuContext, msg) {
+		case selectAction:
 			if settings.enabled {
 				return initSentenceCountBasedTest(settings, menu)
 			}
-		case "left", "h":
+		case leftAction:
 			if settings.cursor > 0 {
 				settings.cursor--
 			}
-		case "right", "l", "tab":
+		case rightAction:
 			if settings.cursor < 2 {
 				settings.cursor++
 			} else {
 				settings.cursor = 0
 			}
-		case "up", "k":
+		case upAction:
 			switch settings.cursor {
 			case 0:
 				if menu.cursor > 0 {
@@ -499,7 +598,7 @@ func (settings SentenceCountBasedTestSettings) handleInput(msg tea.Msg, menu Mai
 					settings.sentenceListCursor = len(settings.sentenceListSelections) - 1
 				}
 			}
-		case "down", "j":
+		case downAction:
 			switch settings.cursor {
 			case 0:
 				if menu.cursor < len(menu.selections)-1 {
@@ -533,11 +632,12 @@ func (configView ConfigView) handleInput(msg tea.Msg, state State) State {
 	embedWordListSectionEnd := len(configView.config.EmbededWordLists)
 	wordListSectionEnd := embedWordListSectionEnd + len(configView.config.WordLists)
 	layoutSectionEnd := wordListSectionEnd + len(configView.config.LayoutFiles)
+	optionSectionEnd := layoutSectionEnd + len(configOptions)
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "e":
+		switch configView.config.keymap.action(configContext, msg) {
+		case toggleAction:
 			switch {
 			case configView.cursor < embedWordListSectionEnd:
 				configView.config.EmbededWordLists[configView.cursor].toggleEnabled()
@@ -551,13 +651,11 @@ func (configView ConfigView) handleInput(msg tea.Msg, state State) State {
 					break
 				}
 
-				if !selected.synced {
-					selected.toggleSynced()
-				}
-
 				if layout, err := selected.getLayout(); err == nil {
 					configView.config.Layout = layout
 				}
+			case configView.cursor < optionSectionEnd:
+				configOptions[configView.cursor-layoutSectionEnd].cycle(&configView.config)
 			}
 
 			// We might not have wordlist that config points to
@@ -565,14 +663,14 @@ func (configView ConfigView) handleInput(msg tea.Msg, state State) State {
 
 			WriteConfig(configView.config)
 			state = configView
-		case "s":
+		case syncAction:
 			switch {
 			case configView.cursor < embedWordListSectionEnd:
 				break
 			case configView.cursor < wordListSectionEnd:
-				configView.config.WordLists[configView.cursor-embedWordListSectionEnd].toggleSynced()
+				configView.config.WordLists[configView.cursor-embedWordListSectionEnd].unsync()
 			case configView.cursor < layoutSectionEnd:
-				configView.config.LayoutFiles[configView.cursor-wordListSectionEnd].toggleSynced()
+				configView.config.LayoutFiles[configView.cursor-wordListSectionEnd].unsync()
 
 			}
 
@@ -581,7 +679,11 @@ func (configView ConfigView) handleInput(msg tea.Msg, state State) State {
 
 			WriteConfig(configView.config)
 			state = configView
-		case "up", "k":
+		case calibrateAction:
+			state = initCalibrationWizard(configView)
+		case editLayoutAction:
+			state = initLayoutEditor(configView.editedLayout(), configView)
+		case upAction:
 			if configView.cursor > 0 {
 				configView.cursor--
 			} else {
@@ -589,7 +691,7 @@ func (configView ConfigView) handleInput(msg tea.Msg, state State) State {
 			}
 
 			state = configView
-		case "down", "j":
+		case downAction:
 			if configView.cursor < configView.config.configTotalSelectionsCount()-1 {
 				configView.cursor++
 			} else {
@@ -597,7 +699,7 @@ func (configView ConfigView) handleInput(msg tea.Msg, state State) State {
 			}
 
 			state = configView
-		case "ctrl+q":
+		case menuAction:
 			state = initMainMenu()
 		}
 	}
@@ -605,48 +707,162 @@ func (configView ConfigView) handleInput(msg tea.Msg, state State) State {
 	return state
 }
 
-func (lay1 *LayoutFile) toggleSynced() {
-	var err error
-	if lay1.synced {
-		err = os.Remove(lay1.Path)
-	} else {
-		// TODO: Move this function to a more general location?
-		err = words.DownloadFile(lay1.Path, lay1.RemoteURI)
+const catalogUpdating = "updating catalog..."
+
+type catalogRefreshedMsg struct {
+	err error
+}
+
+// The download runs in the background, the view keeps taking keys meanwhile
+func (m model) refreshCatalog() (tea.Model, tea.Cmd) {
+	if m.notice == catalogUpdating {
+		return m, nil
+	}
+	m.notice = catalogUpdating
+
+	return m, func() tea.Msg {
+		return catalogRefreshedMsg{err: words.RefreshCatalog(getCachePath())}
 	}
+}
+
+func (configView ConfigView) catalogRefreshed(msg catalogRefreshedMsg) ConfigView {
+	if msg.err == nil {
+		configView.config = ReadConfig()
+	}
+
+	return configView
+}
 
-	if err != nil {
+// Applies the outcome of background work to the config view, also when it waits behind the wizard or the editor.
+// Elsewhere it is dropped, the config view is read again once it is opened.
+func (m model) withConfigView(update func(ConfigView) ConfigView) model {
+	switch state := m.state.(type) {
+	case ConfigView:
+		m.state = update(state)
+	case CalibrationWizard:
+		state.configView = update(state.configView)
+		m.state = state
+	case LayoutEditor:
+		state.configView = update(state.configView)
+		m.state = state
+	}
+
+	return m
+}
+
+type downloadedMsg struct {
+	path         string
+	selectLayout bool // Selected before it was synced, it is selected once it is
+	err          error
+}
+
+func download(path, uri, checksum string, selectLayout bool) tea.Cmd {
+	return func() tea.Msg {
+		return downloadedMsg{path: path, selectLayout: selectLayout, err: words.DownloadFile(path, uri, checksum)}
+	}
+}
+
+// Downloads can take a while with retries, they run in the background and the entry shows as syncing meanwhile.
+// No command when the entry under the cursor has nothing to download.
+func (configView ConfigView) download(selectLayout bool) (ConfigView, tea.Cmd) {
+	wordListSectionStart := len(configView.config.EmbededWordLists)
+	layoutSectionStart := wordListSectionStart + len(configView.config.WordLists)
+
+	switch at := configView.cursor; {
+	case at >= wordListSectionStart && at < layoutSectionStart && !selectLayout:
+		selected := &configView.config.WordLists[at-wordListSectionStart]
+		if selected.isLocal || selected.synced || selected.syncing {
+			return configView, nil
+		}
+		selected.syncing, selected.syncOK = true, true
+
+		return configView, download(selected.Path, selected.RemoteURI, selected.Checksum, false)
+	case at >= layoutSectionStart && at < layoutSectionStart+len(configView.config.LayoutFiles):
+		selected := &configView.config.LayoutFiles[at-layoutSectionStart]
+		if selected.isLocal || selected.Name == "Qwerty" || selected.synced || selected.syncing {
+			return configView, nil
+		}
+		selected.syncing, selected.syncOk = true, true
+
+		return configView, download(selected.Path, selected.RemoteURI, selected.Checksum, selectLayout)
+	}
+
+	return configView, nil
+}
+
+// Entries are found by path, the config may have been read again while downloading
+func (configView ConfigView) downloaded(msg downloadedMsg) ConfigView {
+	for idx := range configView.config.WordLists {
+		if list := &configView.config.WordLists[idx]; list.Path == msg.path {
+			list.syncing = false
+			list.synced, list.syncOK = msg.err == nil, msg.err == nil
+		}
+	}
+	for idx := range configView.config.LayoutFiles {
+		if layoutFile := &configView.config.LayoutFiles[idx]; layoutFile.Path == msg.path {
+			layoutFile.syncing = false
+			layoutFile.synced, layoutFile.syncOk = msg.err == nil, msg.err == nil
+			if !msg.selectLayout {
+				continue
+			}
+			if layout, err := layoutFile.getLayout(); err == nil {
+				configView.config.Layout = layout
+			}
+		}
+	}
+
+	// We might not have wordlist that config points to
+	configView.config.TestSettingCursors.resetWordlistCursors()
+
+	WriteConfig(configView.config)
+
+	return configView
+}
+
+func (lay1 *LayoutFile) unsync() {
+	if lay1.isLocal || !lay1.synced {
+		return
+	}
+
+	if err := os.Remove(lay1.Path); err != nil {
 		lay1.syncOk = false
 	} else {
-		lay1.synced = !lay1.synced
+		lay1.synced = false
 		lay1.syncOk = true
 	}
 }
 
-func (wl *WordList) toggleSynced() {
-	if !wl.isLocal {
-		var err error
-		if wl.synced {
-			err = words.DeleteWordList(wl.Path)
-		} else {
-			err = words.DownloadFile(wl.Path, wl.RemoteURI)
-		}
+func (wl *WordList) unsync() {
+	if wl.isLocal || !wl.synced {
+		return
+	}
 
-		if err != nil {
-			wl.syncOK = false
-		} else {
-			wl.synced = !wl.synced
-			wl.syncOK = true
-		}
+	if err := words.DeleteWordList(wl.Path); err != nil {
+		wl.syncOK = false
+	} else {
+		wl.synced = false
+		wl.syncOK = true
 	}
 }
 
 func (results TimerBasedTestResults) handleInput(msg tea.Msg, state State) State {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "enter", "ctrl+r":
+		switch results.mainMenu.config.keymap.action(resultsContext, msg) {
+		case practiceAction:
+			settings, ok := results.mainMenu.wordCountSettings()
+			if practice := practiceWords(results.panel.words); ok && len(practice) > 0 {
+				state = initPracticeTest(practice, settings, results.mainMenu)
+			}
+		case switchViewAction:
+			results.panel = results.panel.next()
+			state = results
+		case historyAction:
+			results.panel = results.panel.toggleHistorical()
+			state = results
+		case selectAction, restartAction:
 			state = initTimerBasedTest(results.settings, results.mainMenu)
-		case "ctrl+q":
+		case menuAction:
 			state = results.mainMenu
 		}
 	}
@@ -657,10 +873,25 @@ func (results TimerBasedTestResults) handleInput(msg tea.Msg, state State) State
 func (results WordCountTestResults) handleInput(msg tea.Msg, state State) State {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "enter", "ctrl+r":
-			state = initWordCountBasedTest(results.settings, results.mainMenu)
-		case "ctrl+q":
+		switch results.mainMenu.config.keymap.action(resultsContext, msg) {
+		case practiceAction:
+			settings, ok := results.mainMenu.wordCountSettings()
+			if practice := practiceWords(results.panel.words); ok && len(practice) > 0 {
+				state = initPracticeTest(practice, settings, results.mainMenu)
+			}
+		case switchViewAction:
+			results.panel = results.panel.next()
+			state = results
+		case historyAction:
+			results.panel = results.panel.toggleHistorical()
+			state = results
+		case selectAction, restartAction:
+			if results.practice != nil {
+				state = initPracticeTest(results.practice, results.settings, results.mainMenu)
+			} else {
+				state = initWordCountBasedTest(results.settings, results.mainMenu)
+			}
+		case menuAction:
 			state = results.mainMenu
 		}
 	}
@@ -671,10 +902,21 @@ func (results WordCountTestResults) handleInput(msg tea.Msg, state State) State
 func (results SentenceCountTestResults) handleInput(msg tea.Msg, state State) State {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "enter", "ctrl+r":
+		switch results.mainMenu.config.keymap.action(resultsContext, msg) {
+		case practiceAction:
+			settings, ok := results.mainMenu.wordCountSettings()
+			if practice := practiceWords(results.panel.words); ok && len(practice) > 0 {
+				state = initPracticeTest(practice, settings, results.mainMenu)
+			}
+		case switchViewAction:
+			results.panel = results.panel.next()
+			state = results
+		case historyAction:
+			results.panel = results.panel.toggleHistorical()
+			state = results
+		case selectAction, restartAction:
 			state = initSentenceCountBasedTest(results.settings, results.mainMenu)
-		case "ctrl+q":
+		case menuAction:
 			state = results.mainMenu
 		}
 	}
@@ -683,31 +925,63 @@ func (results SentenceCountTestResults) handleInput(msg tea.Msg, state State) St
 }
 
 func handleBackspace(base *TestBase) {
-	base.inputBuffer = dropLastRune(base.inputBuffer)
+	if base.locked() {
+		return
+	}
+	base.record(backspaceEvent, 0, 0)
+
+	if len(base.pending) > 0 {
+		base.pending = dropLastRune(base.pending)
+		return
+	}
 
-	//Delete mistakes
 	inputLength := len(base.inputBuffer)
-	_, ok := base.mistakes.mistakesAt[inputLength]
-	if ok {
-		delete(base.mistakes.mistakesAt, inputLength)
+	if extra := base.overflow[inputLength]; len(extra) > 0 {
+		base.overflow[inputLength] = extra[:len(extra)-1]
+		return
+	}
+
+	droppedSpace := inputLength > 0 && base.inputBuffer[inputLength-1] == ' '
+	base.inputBuffer = dropLastGrapheme(base.inputBuffer)
+
+	// Back over a skipped word lands after its last typed letter
+	if droppedSpace {
+		for len(base.inputBuffer) > 0 && base.mistakes.omittedAt[len(base.inputBuffer)-1] {
+			base.inputBuffer = base.inputBuffer[:len(base.inputBuffer)-1]
+		}
+	}
+
+	base.dropMistakesFrom(len(base.inputBuffer))
+}
+
+// Forgets mistakes at or after the given position and moves the cursor there
+func (base *TestBase) dropMistakesFrom(inputLength int) {
+	for at := range base.mistakes.mistakesAt {
+		if at >= inputLength {
+			delete(base.mistakes.mistakesAt, at)
+			delete(base.mistakes.omittedAt, at)
+		}
+	}
+	for at := range base.overflow {
+		if at > inputLength {
+			delete(base.overflow, at)
+		}
 	}
 
 	base.cursor = inputLength
 }
 
 func handleCtrlW(base *TestBase) {
+	if base.locked() {
+		return
+	}
+	base.record(deleteWordEvent, 0, 0)
+	base.pending = nil
 	base.inputBuffer = dropUntilWsIdx(base.inputBuffer, base.findLatestWsIndex())
 	bufferLen := len(base.inputBuffer)
-	base.cursor = bufferLen
 
-	//Delete mistakes
-	var newMistakes map[int]bool = make(map[int]bool, 0)
-	for at := range base.mistakes.mistakesAt {
-		if at < bufferLen {
-			newMistakes[at] = true
-		}
-	}
-	base.mistakes.mistakesAt = newMistakes
+	base.dropMistakesFrom(bufferLen)
+	delete(base.overflow, bufferLen)
 }
 
 func dropUntilWsIdx(input []rune, wsIdx int) []rune {
@@ -718,14 +992,46 @@ func dropUntilWsIdx(input []rune, wsIdx int) []rune {
 	}
 }
 
-func handleRunes(msg tea.KeyMsg, base *TestBase, remappedInput map[rune]rune) {
-	inputLetter := msg.Runes[len(msg.Runes)-1]
+func handleRunes(msg tea.KeyMsg, base *TestBase, layout Layout) {
+	if base.locked() {
+		return
+	}
+	if isPaste(msg) {
+		base.pasted = true
+	}
+
+	// Multi rune clusters (e.g. emojis) arrive in a single message
+	for _, typed := range msg.Runes {
+		var ready []rune
+		// Alt is the meta prefix of the terminal, AltGr can't be told apart from the rune it composes
+		mapped := layout.remap(typed, msg.Alt)
+		ready, base.pending = layout.feed(base.pending, mapped)
 
+		if len(ready) == 0 {
+			base.record(pendingEvent, typed, mapped)
+		}
+		for _, letter := range ready {
+			base.record(runeEvent, typed, letter)
+			inputRune(base, letter)
+		}
+	}
+}
+
+func inputRune(base *TestBase, inputLetter rune) {
 	inputLenDec := len(base.inputBuffer)
+	if inputLenDec >= len(base.wordsToEnter) {
+		return
+	}
 	letterToInput := base.wordsToEnter[inputLenDec]
 
-	if r, ok := remappedInput[inputLetter]; ok {
-		inputLetter = r
+	// Letters past the end of a word pile up in front of the space instead of taking its place
+	if base.skipWords && letterToInput == ' ' && inputLetter != ' ' {
+		if len(base.overflow[inputLenDec]) < maxOverflow {
+			base.overflow[inputLenDec] = append(base.overflow[inputLenDec], inputLetter)
+			base.rawInputCnt += 1
+			base.mistakes.rawMistakesCnt += 1
+		}
+		return
 	}
 
 	base.inputBuffer = append(base.inputBuffer, inputLetter)
@@ -742,8 +1048,22 @@ func handleRunes(msg tea.KeyMsg, base *TestBase, remappedInput map[rune]rune) {
 	base.cursor = lenAfterAppend
 }
 
-func handleSpace(base *TestBase) {
-	if len(base.inputBuffer) > 0 {
+func handleSpace(base *TestBase, layout Layout) {
+	if base.locked() {
+		return
+	}
+	for _, letter := range layout.flush(base.pending) {
+		base.record(runeEvent, ' ', letter)
+		inputRune(base, letter)
+	}
+	base.pending = nil
+
+	if base.skipWords && skipWord(base) {
+		return
+	}
+
+	if len(base.inputBuffer) > 0 && len(base.inputBuffer) < len(base.wordsToEnter) {
+		base.record(spaceEvent, ' ', ' ')
 		base.inputBuffer = append(base.inputBuffer, ' ')
 		base.cursor = len(base.inputBuffer)
 		base.rawInputCnt += 1
@@ -759,6 +1079,39 @@ func handleSpace(base *TestBase) {
 	}
 }
 
+// Extra letters shown for a single word, the rest are dropped
+const maxOverflow = 20
+
+// Marks the rest of a started word as missed and enters the space after it.
+// Returns false when the space should be entered as usual.
+func skipWord(base *TestBase) bool {
+	at := len(base.inputBuffer)
+	if at >= len(base.wordsToEnter) || base.wordsToEnter[at] == ' ' {
+		return false
+	}
+	// Nothing to skip before the word is started
+	if at == 0 || base.wordsToEnter[at-1] == ' ' {
+		return true
+	}
+
+	// Every skipped letter counts as an input and a mistake, like a wrong letter typed in its place
+	base.record(spaceEvent, ' ', ' ')
+	for ; at < len(base.wordsToEnter) && base.wordsToEnter[at] != ' '; at++ {
+		base.inputBuffer = append(base.inputBuffer, base.wordsToEnter[at])
+		base.mistakes.mistakesAt[at] = true
+		base.mistakes.omittedAt[at] = true
+		base.mistakes.rawMistakesCnt += 1
+		base.rawInputCnt += 1
+	}
+	if at < len(base.wordsToEnter) {
+		base.inputBuffer = append(base.inputBuffer, ' ')
+	}
+	base.rawInputCnt += 1
+	base.cursor = len(base.inputBuffer)
+
+	return true
+}
+
 func (base *TestBase) findLatestWsIndex() int {
 	var wsIdx int = 0
 	for idx, value := range base.wordsToEnter {
@@ -2,13 +2,15 @@ package cmd
 
 import (
 	"math"
+
+	"github.com/rivo/uniseg"
 )
 
 func longestStringLen(strings []string) int {
 	var longest int
 	for _, elem := range strings {
-		length := len(elem)
-		if len(elem) > longest {
+		length := stringWidth(elem)
+		if length > longest {
 			longest = length
 		}
 	}
@@ -31,7 +33,7 @@ func averageStringLen(strings []string) int {
 	var cnt int = 0
 
 	for _, str := range strings {
-		currentLen := len([]rune(dropAnsiCodes(str)))
+		currentLen := stringWidth(str)
 		totalLen += currentLen
 		cnt += 1
 	}
@@ -65,6 +67,21 @@ func dropLastRune(runes []rune) []rune {
 	}
 }
 
+func dropLastGrapheme(runes []rune) []rune {
+	graphemes := uniseg.NewGraphemes(string(runes))
+	lastLen := 0
+	for graphemes.Next() {
+		lastLen = len(graphemes.Runes())
+	}
+
+	return runes[:len(runes)-lastLen]
+}
+
+// Width in terminal cells, ignoring styling
+func stringWidth(str string) int {
+	return uniseg.StringWidth(dropAnsiCodes(str))
+}
+
 func toKeysSlice(mp map[int]bool) []int {
 	acc := []int{}
 	for key := range mp {
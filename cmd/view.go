@@ -4,14 +4,13 @@ import (
 	"fmt"
 	"math"
 	"regexp"
-	"sort"
 	"strconv"
 	"strings"
 
+	"github.com/bloznelis/typioca/cmd/words"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/guptarohit/asciigraph"
 	"github.com/muesli/reflow/indent"
-	"github.com/muesli/reflow/wordwrap"
 )
 
 var lineLenLimit int
@@ -90,6 +89,22 @@ func (m model) View() string {
 
 		return lipgloss.Place(termWidth, termHeight, lipgloss.Center, lipgloss.Center, s)
 
+	case StatsView:
+		view := m.statsView(state, max(m.height-12, 1))
+		help := style(fmt.Sprintf("%s switch view, %s to menu", m.keymap.key(rightAction), m.keymap.key(menuAction)), m.styles.toEnter)
+		help = lipgloss.NewStyle().Align(lipgloss.Center).Padding(1).Render(help)
+		view = lipgloss.NewStyle().Align(lipgloss.Left).Render(view)
+
+		all := lipgloss.JoinVertical(lipgloss.Center, view, help)
+
+		return lipgloss.Place(termWidth, termHeight, lipgloss.Center, lipgloss.Center, all)
+
+	case CalibrationWizard:
+		return m.calibrationView(state)
+
+	case LayoutEditor:
+		return m.layoutEditorView(state)
+
 	case ConfigView:
 		absolutePad := longestStringLen(names(state.config.WordLists)) + 2
 		var view string
@@ -102,7 +117,13 @@ func (m model) View() string {
 		view += wordlistHeader
 
 		accumulatedLength := len(state.config.EmbededWordLists)
+		previousLanguage := ""
 		for idx, elem := range state.config.EmbededWordLists {
+			if idx == 0 || elem.Language != previousLanguage {
+				view += m.languageHeader(state.config.language(elem.Language))
+				previousLanguage = elem.Language
+			}
+
 			var enabled string
 			if elem.Enabled {
 				enabled = "x"
@@ -111,7 +132,7 @@ func (m model) View() string {
 			}
 			enabled = style(enabled, m.styles.greener)
 
-			toPad := absolutePad - len(elem.Name)
+			toPad := absolutePad - stringWidth(elem.Name)
 			line := fmt.Sprintf("%s%*s     [%s] ", style(elem.Name, m.styles.greener), toPad, "", enabled)
 
 			view += wrapWithCursor(idx == state.cursor, line, m.styles.runningTimer)
@@ -119,6 +140,7 @@ func (m model) View() string {
 		}
 		view += "\n"
 
+		wordListHeaderShown := false
 		view += renderSelectionWindow(
 			sectionMaxAmountToShow,
 			state.cursor,
@@ -126,6 +148,13 @@ func (m model) View() string {
 			m.styles.toEnter,
 			state.config.WordLists,
 			func(elem WordList, isCursorOnLine bool) string {
+				var header string
+				if !wordListHeaderShown || elem.Language != previousLanguage {
+					header = m.languageHeader(state.config.language(elem.Language))
+					wordListHeaderShown = true
+					previousLanguage = elem.Language
+				}
+
 				var synced string
 				if elem.synced {
 					synced = "x"
@@ -151,13 +180,16 @@ func (m model) View() string {
 					enabled = style(enabled, m.styles.toEnter)
 				}
 
-				toPad := absolutePad - len(elem.Name)
+				toPad := absolutePad - stringWidth(elem.Name)
 				line := fmt.Sprintf("%s%*s[%s]  [%s] ", style(elem.Name, m.styles.greener), toPad, "", synced, enabled)
 				if !elem.syncOK {
 					line = style(dropAnsiCodes(line), m.styles.mistakes)
 				}
+				if elem.syncing {
+					line += style("syncing...", m.styles.toEnter)
+				}
 
-				lineContents := wrapWithCursor(isCursorOnLine, line, m.styles.runningTimer)
+				lineContents := header + wrapWithCursor(isCursorOnLine, line, m.styles.runningTimer)
 				lineContents += "\n"
 
 				return lineContents
@@ -190,12 +222,29 @@ func (m model) View() string {
 					enabled = " "
 				}
 
-				toPad := absolutePad - len(elem.Name)
+				if elem.isLocal {
+					synced = style(synced, m.styles.toEnter)
+					enabled = style(enabled, m.styles.toEnter)
+				}
+
+				toPad := absolutePad - stringWidth(elem.Name)
 				line := fmt.Sprintf("%s%*s[%s]  [%s] ", style(elem.Name, m.styles.greener), toPad, "", synced, enabled)
 
 				if elem.Name == "Qwerty" {
 					line = fmt.Sprintf("%s%*s     [%s] ", style(elem.Name, m.styles.greener), toPad, "", enabled)
 				}
+				if !elem.syncOk {
+					line = style(dropAnsiCodes(line), m.styles.mistakes)
+				}
+				if elem.syncing {
+					line += style("syncing...", m.styles.toEnter)
+				}
+				if isCursorOnLine && elem.problem != "" {
+					line += style(elem.problem, m.styles.mistakes)
+				}
+				if isCursorOnLine && elem.Name == state.config.Layout.Name && len(state.config.Layout.AltMappings) > 0 {
+					line += style("alt layer: hold Alt, not AltGr", m.styles.toEnter)
+				}
 
 				lineContent := wrapWithCursor(isCursorOnLine, line, m.styles.runningTimer)
 				lineContent += "\n"
@@ -205,7 +254,21 @@ func (m model) View() string {
 		view += "\n"
 		accumulatedLength += len(state.config.LayoutFiles)
 
-		help := style("s sync/delete, e enable/disable, ctrl+q to menu", m.styles.toEnter)
+		view += "\n"
+		view += "  settings\n\n"
+		for idx, option := range configOptions {
+			toPad := absolutePad - stringWidth(option.name)
+			line := fmt.Sprintf("%s%*s[%s] ", style(option.name, m.styles.greener), toPad, "", style(option.get(state.config), m.styles.greener))
+
+			view += wrapWithCursor(idx+accumulatedLength == state.cursor, line, m.styles.runningTimer)
+			view += "\n"
+		}
+		accumulatedLength += len(configOptions)
+
+		help := style(fmt.Sprintf("%s sync/delete, %s enable/disable/change, %s calibrate layout, %s edit layout, %s update catalog, %s to menu", m.keymap.key(syncAction), m.keymap.key(toggleAction), m.keymap.key(calibrateAction), m.keymap.key(editLayoutAction), m.keymap.key(catalogAction), m.keymap.key(menuAction)), m.styles.toEnter)
+		if m.notice != "" {
+			help = style(m.notice, m.styles.greener) + "\n" + help
+		}
 		help = lipgloss.NewStyle().Align(lipgloss.Center).Padding(1).Render(help)
 		view = lipgloss.NewStyle().Align(lipgloss.Left).Render(view)
 
@@ -215,7 +278,7 @@ func (m model) View() string {
 
 	case TimerBasedTestResults:
 		rawWpmShow := "raw: " + style(strconv.Itoa(state.results.rawWpm), m.styles.greener)
-		wpm := "wpm: " + style(strconv.Itoa(state.results.wpm), m.styles.runningTimer)
+		wpm := state.results.formula.unit() + ": " + style(strconv.Itoa(state.results.wpm), m.styles.runningTimer)
 		deltaWpm := "Δavg: " + style(fmt.Sprintf("%s%.2f%%", plusIfPositive(state.results.deltaWpm), math.Min(state.results.deltaWpm, 100.0)), m.styles.greener)
 		givenTime := "time: " + style(state.results.time.String(), m.styles.greener)
 		accuracy := "accuracy: " + style(fmt.Sprintf("%.1f", state.results.accuracy), m.styles.greener)
@@ -223,17 +286,20 @@ func (m model) View() string {
 
 		miscStatsLine1 := fmt.Sprintf("%s %s %s %s", accuracy, deltaWpm, rawWpmShow, givenTime)
 		miscStatsLine2 := words
+		miscStatsLine3 := m.metricsLine(state.results)
+		miscStatsLine4 := "mistakes: " + style(state.results.errorTypes.String(), m.styles.greener)
+		miscStatsLine4 += m.flagsLine(state.results.flags)
 
-		miscStatsLine1Len := len(dropAnsiCodes(miscStatsLine1))
+		miscStatsLine1Len := stringWidth(miscStatsLine1)
 		plotData := append(state.wpmEachSecond, float64(state.results.wpm))
-		wpmsPlot := plotWpms(plotData, miscStatsLine1Len-2)
+		wpmsPlot := m.resultsPanelView(state.panel, state.mainMenu.config.Layout, plotData, miscStatsLine1Len-2)
 
-		fullParagraph := lipgloss.JoinVertical(lipgloss.Center, resultsStyle.Padding(1).Render(wpm), wpmsPlot, resultsStyle.Padding(0).Render(miscStatsLine1), resultsStyle.Render(miscStatsLine2))
+		fullParagraph := lipgloss.JoinVertical(lipgloss.Center, resultsStyle.Padding(1).Render(wpm), wpmsPlot, resultsStyle.Padding(0).Render(miscStatsLine1), resultsStyle.Render(miscStatsLine2), resultsStyle.Render(miscStatsLine3), resultsStyle.Render(miscStatsLine4))
 		s = lipgloss.Place(termWidth, termHeight, lipgloss.Center, lipgloss.Center, fullParagraph)
 
 	case WordCountTestResults:
 		rawWpmShow := "raw: " + style(strconv.Itoa(state.results.rawWpm), m.styles.greener)
-		wpm := "wpm: " + style(strconv.Itoa(state.results.wpm), m.styles.runningTimer)
+		wpm := state.results.formula.unit() + ": " + style(strconv.Itoa(state.results.wpm), m.styles.runningTimer)
 		deltaWpm := "Δavg: " + style(fmt.Sprintf("%s%.2f%%", plusIfPositive(state.results.deltaWpm), math.Min(state.results.deltaWpm, 100.0)), m.styles.greener)
 		givenTime := "time: " + style(state.results.time.String(), m.styles.greener)
 		wordCnt := "cnt: " + style(strconv.Itoa(state.wordCnt), m.styles.greener)
@@ -242,18 +308,21 @@ func (m model) View() string {
 
 		miscStatsLine1 := fmt.Sprintf("%s %s %s %s", accuracy, deltaWpm, rawWpmShow, givenTime)
 		miscStatsLine2 := wordCnt + " " + words
+		miscStatsLine3 := m.metricsLine(state.results)
+		miscStatsLine4 := "mistakes: " + style(state.results.errorTypes.String(), m.styles.greener)
+		miscStatsLine4 += m.flagsLine(state.results.flags)
 
-		miscStatsLine1Len := len(dropAnsiCodes(miscStatsLine1))
+		miscStatsLine1Len := stringWidth(miscStatsLine1)
 
 		plotData := append(state.wpmEachSecond, float64(state.results.wpm))
-		wpmsPlot := plotWpms(plotData, miscStatsLine1Len-2)
+		wpmsPlot := m.resultsPanelView(state.panel, state.mainMenu.config.Layout, plotData, miscStatsLine1Len-2)
 
-		fullParagraph := lipgloss.JoinVertical(lipgloss.Center, resultsStyle.Padding(1).Render(wpm), wpmsPlot, resultsStyle.Padding(0).Render(miscStatsLine1), resultsStyle.Render(miscStatsLine2))
+		fullParagraph := lipgloss.JoinVertical(lipgloss.Center, resultsStyle.Padding(1).Render(wpm), wpmsPlot, resultsStyle.Padding(0).Render(miscStatsLine1), resultsStyle.Render(miscStatsLine2), resultsStyle.Render(miscStatsLine3), resultsStyle.Render(miscStatsLine4))
 		s = lipgloss.Place(termWidth, termHeight, lipgloss.Center, lipgloss.Center, fullParagraph)
 
 	case SentenceCountTestResults:
 		rawWpmShow := "raw: " + style(strconv.Itoa(state.results.rawWpm), m.styles.greener)
-		wpm := "wpm: " + style(strconv.Itoa(state.results.wpm), m.styles.runningTimer)
+		wpm := state.results.formula.unit() + ": " + style(strconv.Itoa(state.results.wpm), m.styles.runningTimer)
 		deltaWpm := "Δavg: " + style(fmt.Sprintf("%s%.2f%%", plusIfPositive(state.results.deltaWpm), math.Min(state.results.deltaWpm, 100.0)), m.styles.greener)
 		givenTime := "time: " + style(state.results.time.String(), m.styles.greener)
 		sentenceCnt := "cnt: " + style(strconv.Itoa(state.sentenceCnt), m.styles.greener)
@@ -262,12 +331,15 @@ func (m model) View() string {
 
 		miscStatsLine1 := fmt.Sprintf("%s %s %s %s", accuracy, deltaWpm, rawWpmShow, givenTime)
 		miscStatsLine2 := sentenceCnt + " " + words
+		miscStatsLine3 := m.metricsLine(state.results)
+		miscStatsLine4 := "mistakes: " + style(state.results.errorTypes.String(), m.styles.greener)
+		miscStatsLine4 += m.flagsLine(state.results.flags)
 
-		miscStatsLine1Len := len(dropAnsiCodes(miscStatsLine1))
+		miscStatsLine1Len := stringWidth(miscStatsLine1)
 		plotData := append(state.wpmEachSecond, float64(state.results.wpm))
-		wpmsPlot := plotWpms(plotData, miscStatsLine1Len-2)
+		wpmsPlot := m.resultsPanelView(state.panel, state.mainMenu.config.Layout, plotData, miscStatsLine1Len-2)
 
-		fullParagraph := lipgloss.JoinVertical(lipgloss.Center, resultsStyle.Padding(1).Render(wpm), wpmsPlot, resultsStyle.Padding(0).Render(miscStatsLine1), resultsStyle.Render(miscStatsLine2))
+		fullParagraph := lipgloss.JoinVertical(lipgloss.Center, resultsStyle.Padding(1).Render(wpm), wpmsPlot, resultsStyle.Padding(0).Render(miscStatsLine1), resultsStyle.Render(miscStatsLine2), resultsStyle.Render(miscStatsLine3), resultsStyle.Render(miscStatsLine4))
 		s = lipgloss.Place(termWidth, termHeight, lipgloss.Center, lipgloss.Center, fullParagraph)
 
 	case TimerBasedTest:
@@ -277,10 +349,11 @@ func (m model) View() string {
 		} else {
 			coloredTimer = style(state.timer.timer.View(), m.styles.stoppedTimer)
 		}
+		if !state.base.gate.open {
+			coloredTimer = style(state.base.gate.view(m.keymap), m.styles.runningTimer)
+		}
 
-		paragraphView := state.base.paragraphView(lineLenLimit, m.styles)
-		lines := strings.Split(paragraphView, "\n")
-		cursorLine := findCursorLine(lines, state.base.cursor)
+		lines, cursorLine := state.base.paragraphView(lineLenLimit, m.styles)
 
 		linesAroundCursor := strings.Join(getLinesAroundCursor(lines, cursorLine), "\n")
 
@@ -289,23 +362,25 @@ func (m model) View() string {
 		indentBy := uint(math.Max(0, float64(termWidth/2-avgLineLen/2)))
 
 		s += m.indent(coloredTimer, indentBy) + "\n\n" + m.indent(linesAroundCursor, indentBy)
+		s += m.keyboardView(state.base, state.mainMenu.config, termWidth, termHeight-strings.Count(s, "\n")-4)
 
 		if !state.timer.isRunning {
 			s += "\n\n\n"
-			s += lipgloss.PlaceHorizontal(termWidth, lipgloss.Center, style("ctrl+r to restart, ctrl+q to menu", m.styles.toEnter))
+			s += lipgloss.PlaceHorizontal(termWidth, lipgloss.Center, style(fmt.Sprintf("%s to restart, %s to menu", m.keymap.key(restartAction), m.keymap.key(menuAction)), m.styles.toEnter))
 		}
 
 	case WordCountBasedTest:
 		var coloredStopwatch string
-		if state.stopwatch.isRunning {
+		if state.stopwatch.isRunning && !state.base.paused {
 			coloredStopwatch = style(state.stopwatch.stopwatch.View(), m.styles.runningTimer)
 		} else {
 			coloredStopwatch = style(state.stopwatch.stopwatch.View(), m.styles.stoppedTimer)
 		}
+		if !state.base.gate.open {
+			coloredStopwatch = style(state.base.gate.view(m.keymap), m.styles.runningTimer)
+		}
 
-		paragraphView := state.base.paragraphView(lineLenLimit, m.styles)
-		lines := strings.Split(paragraphView, "\n")
-		cursorLine := findCursorLine(strings.Split(paragraphView, "\n"), state.base.cursor)
+		lines, cursorLine := state.base.paragraphView(lineLenLimit, m.styles)
 
 		linesAroundCursor := strings.Join(getLinesAroundCursor(lines, cursorLine), "\n")
 
@@ -314,23 +389,28 @@ func (m model) View() string {
 		indentBy := uint(math.Max(0, float64(termWidth/2-avgLineLen/2)))
 
 		s += m.indent(coloredStopwatch, indentBy) + "\n\n" + m.indent(linesAroundCursor, indentBy)
+		s += m.keyboardView(state.base, state.mainMenu.config, termWidth, termHeight-strings.Count(s, "\n")-4)
 
-		if !state.stopwatch.isRunning {
+		if state.base.paused {
+			s += "\n\n\n"
+			s += lipgloss.PlaceHorizontal(termWidth, lipgloss.Center, style(fmt.Sprintf("paused, %s to resume, %s to restart, %s to menu", m.keymap.key(pauseAction), m.keymap.key(restartAction), m.keymap.key(menuAction)), m.styles.toEnter))
+		} else if !state.stopwatch.isRunning {
 			s += "\n\n\n"
-			s += lipgloss.PlaceHorizontal(termWidth, lipgloss.Center, style("ctrl+r to restart, ctrl+q to menu", m.styles.toEnter))
+			s += lipgloss.PlaceHorizontal(termWidth, lipgloss.Center, style(fmt.Sprintf("%s to restart, %s to menu", m.keymap.key(restartAction), m.keymap.key(menuAction)), m.styles.toEnter))
 		}
 
 	case SentenceCountBasedTest:
 		var coloredStopwatch string
-		if state.stopwatch.isRunning {
+		if state.stopwatch.isRunning && !state.base.paused {
 			coloredStopwatch = style(state.stopwatch.stopwatch.View(), m.styles.runningTimer)
 		} else {
 			coloredStopwatch = style(state.stopwatch.stopwatch.View(), m.styles.stoppedTimer)
 		}
+		if !state.base.gate.open {
+			coloredStopwatch = style(state.base.gate.view(m.keymap), m.styles.runningTimer)
+		}
 
-		paragraphView := state.base.paragraphView(lineLenLimit, m.styles)
-		lines := strings.Split(paragraphView, "\n")
-		cursorLine := findCursorLine(lines, state.base.cursor)
+		lines, cursorLine := state.base.paragraphView(lineLenLimit, m.styles)
 
 		linesAroundCursor := strings.Join(getLinesAroundCursor(lines, cursorLine), "\n")
 
@@ -339,10 +419,14 @@ func (m model) View() string {
 
 		s += positionVerticaly(termHeight)
 		s += m.indent(coloredStopwatch, indentBy) + "\n\n" + m.indent(linesAroundCursor, indentBy)
+		s += m.keyboardView(state.base, state.mainMenu.config, termWidth, termHeight-strings.Count(s, "\n")-4)
 
-		if !state.stopwatch.isRunning {
+		if state.base.paused {
 			s += "\n\n\n"
-			s += lipgloss.PlaceHorizontal(termWidth, lipgloss.Center, style("ctrl+r to restart, ctrl+q to menu", m.styles.toEnter))
+			s += lipgloss.PlaceHorizontal(termWidth, lipgloss.Center, style(fmt.Sprintf("paused, %s to resume, %s to restart, %s to menu", m.keymap.key(pauseAction), m.keymap.key(restartAction), m.keymap.key(menuAction)), m.styles.toEnter))
+		} else if !state.stopwatch.isRunning {
+			s += "\n\n\n"
+			s += lipgloss.PlaceHorizontal(termWidth, lipgloss.Center, style(fmt.Sprintf("%s to restart, %s to menu", m.keymap.key(restartAction), m.keymap.key(menuAction)), m.styles.toEnter))
 		}
 
 	}
@@ -350,6 +434,15 @@ func (m model) View() string {
 	return s
 }
 
+func (m model) languageHeader(language words.Language) string {
+	header := "  " + language.Name
+	if language.SuggestedLayout != "" {
+		header += ", suggested layout: " + language.SuggestedLayout
+	}
+
+	return style(header, m.styles.toEnter) + "\n"
+}
+
 func plusIfPositive(f float64) string {
 	if f > 0.0 {
 		return "+"
@@ -381,6 +474,41 @@ func plotWpms(wpms []float64, width int) string {
 	return lipgloss.NewStyle().Padding(1).Render(wpmGraph)
 }
 
+func (m model) flagsLine(flags []string) string {
+	if len(flags) == 0 {
+		return ""
+	}
+
+	return "\n" + style("not counted in averages: "+strings.Join(flags, ", "), m.styles.mistakes)
+}
+
+func (m model) metricsLine(results Results) string {
+	consistency := "consistency: " + style(fmt.Sprintf("%.0f%%", results.metrics.consistency), m.styles.greener)
+	burst := "burst: " + style(strconv.Itoa(int(results.metrics.burst)), m.styles.greener)
+	errors := "errors: " + style(fmt.Sprintf("%d corrected, %d not", results.metrics.corrected, results.metrics.uncorrected), m.styles.greener)
+
+	return fmt.Sprintf("%s %s %s", consistency, burst, errors)
+}
+
+func plotErrors(errors []float64, width int) string {
+	if len(errors) == 0 {
+		errors = []float64{0}
+	}
+
+	errorsGraph := asciigraph.Plot(
+		errors,
+		asciigraph.Precision(0),
+		asciigraph.Height(5),
+		asciigraph.Width(width),
+		asciigraph.SeriesColors(asciigraph.Red),
+		asciigraph.LabelColor(1),
+		asciigraph.Caption("errors per second"),
+		asciigraph.CaptionColor(1),
+	)
+
+	return lipgloss.NewStyle().Padding(1).Render(errorsGraph)
+}
+
 func averageLineLenFast(lines []string) int {
 	linesLen := len(lines)
 	linesToConsider := int(math.Min(float64(linesLen), 3))
@@ -479,104 +607,6 @@ func (m model) indent(block string, indentBy uint) string {
 	return indentedBlock
 }
 
-func (base *TestBase) paragraphView(lineLimit int, styles Styles) string {
-	paragraph := base.colorInput(styles)
-	paragraph += base.colorCursor(styles)
-	paragraph += base.colorWordsToEnter(styles)
-
-	wrapped := wrapStyledParagraph(paragraph, lineLimit)
-
-	return wrapped
-}
-
-func (base *TestBase) colorInput(styles Styles) string {
-	mistakes := toKeysSlice(base.mistakes.mistakesAt)
-	sort.Ints(mistakes)
-
-	var coloredInput strings.Builder
-
-	if len(mistakes) == 0 {
-
-		coloredInput.WriteString(styleAllRunes(base.inputBuffer, styles.correct))
-
-	} else {
-
-		previousMistake := -1
-
-		for _, mistakeAt := range mistakes {
-			sliceUntilMistake := base.inputBuffer[previousMistake+1 : mistakeAt]
-			mistakeSlice := base.wordsToEnter[mistakeAt : mistakeAt+1]
-
-			coloredInput.WriteString(styleAllRunes(sliceUntilMistake, styles.correct))
-			coloredInput.WriteString(style(string(mistakeSlice), styles.mistakes))
-
-			previousMistake = mistakeAt
-		}
-
-		inputAfterLastMistake := base.inputBuffer[previousMistake+1:]
-		coloredInput.WriteString(styleAllRunes(inputAfterLastMistake, styles.correct))
-	}
-
-	return coloredInput.String()
-}
-
-func (base *TestBase) colorCursor(styles Styles) string {
-	cursorLetter := base.wordsToEnter[len(base.inputBuffer) : len(base.inputBuffer)+1]
-
-	return style(string(cursorLetter), styles.cursor)
-}
-
-func (base *TestBase) colorWordsToEnter(styles Styles) string {
-	wordsToEnter := base.wordsToEnter[len(base.inputBuffer)+1:] // without cursor
-
-	return style(string(wordsToEnter), styles.toEnter)
-}
-
-func wrapStyledParagraph(paragraph string, lineLimit int) string {
-	// XXX: Replace spaces, because wordwrap trims them out at the ends
-	paragraph = strings.ReplaceAll(paragraph, " ", "·")
-
-	f := wordwrap.NewWriter(lineLimit)
-	f.Breakpoints = []rune{'·'}
-	f.KeepNewlines = false
-	f.Write([]byte(paragraph))
-	f.Close()
-
-	paragraph = strings.ReplaceAll(f.String(), "·", " ")
-
-	return paragraph
-}
-
-func findCursorLine(lines []string, cursorAt int) int {
-	lenAcc := 0
-	cursorLine := 0
-
-	for _, line := range lines {
-		lineLen := len(dropAnsiCodes(line))
-
-		lenAcc += lineLen
-
-		if cursorAt <= lenAcc-1 {
-			return cursorLine
-		} else {
-			cursorLine += 1
-		}
-	}
-
-	return cursorLine
-}
-
 func style(str string, style StringStyle) string {
 	return style(str).String()
 }
-
-func styleAllRunes(runes []rune, style StringStyle) string {
-	var acc strings.Builder
-
-	for idx, char := range runes {
-		_ = idx
-		acc.WriteString(style(string(char)).String())
-	}
-
-	return acc.String()
-}
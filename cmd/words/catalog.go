@@ -0,0 +1,105 @@
+package words
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Catalog format this build understands, catalogs of another format are ignored
+const CatalogFormat = 1
+
+const CatalogURI = "https://raw.githubusercontent.com/bloznelis/typioca/master/cmd/words/embedables/catalog.json"
+
+// How old the cached catalog may get before it is refreshed in the background
+const CatalogMaxAge = 7 * 24 * time.Hour
+
+//go:embed embedables/catalog.json
+var embededCatalog []byte
+
+// Official word lists and layouts that can be synced
+type Catalog struct {
+	Format    int               `json:"format"`
+	Revision  int               `json:"revision"` // Bumped on every change, the newer of the cached and embedded catalogs wins
+	WordLists []CatalogWordList `json:"wordLists"`
+	Layouts   []CatalogLayout   `json:"layouts"`
+}
+
+type CatalogEntry struct {
+	Name    string `json:"name"`
+	File    string `json:"file"`
+	Size    int64  `json:"size"`
+	Version int    `json:"version"`
+	Sha256  string `json:"sha256"`
+}
+
+type CatalogWordList struct {
+	CatalogEntry
+	Sentences bool   `json:"sentences"`
+	Language  string `json:"language"`
+	Enabled   bool   `json:"enabled"` // Whether the list starts enabled
+}
+
+type CatalogLayout struct {
+	CatalogEntry
+}
+
+func CatalogPath(cachePath string) string {
+	return filepath.Join(cachePath, "catalog.json")
+}
+
+func parseCatalog(data []byte) (Catalog, error) {
+	var catalog Catalog
+	if err := json.Unmarshal(data, &catalog); err != nil {
+		return Catalog{}, err
+	}
+	if catalog.Format != CatalogFormat {
+		return Catalog{}, fmt.Errorf("unsupported catalog format %d", catalog.Format)
+	}
+
+	return catalog, nil
+}
+
+// The cached catalog when it is readable and newer than the embedded one
+func LoadCatalog(cachePath string) Catalog {
+	catalog, err := parseCatalog(embededCatalog)
+	if err != nil {
+		panic(err)
+	}
+
+	if data, err := os.ReadFile(CatalogPath(cachePath)); err == nil {
+		if cached, err := parseCatalog(data); err == nil && cached.Revision > catalog.Revision {
+			return cached
+		}
+	}
+
+	return catalog
+}
+
+// Downloads the latest catalog into the cache, a broken download leaves the cached one in place
+func RefreshCatalog(cachePath string) error {
+	path := CatalogPath(cachePath)
+	fresh := path + ".new"
+	if err := DownloadFile(fresh, CatalogURI, ""); err != nil {
+		return err
+	}
+	defer os.Remove(fresh)
+
+	data, err := os.ReadFile(fresh)
+	if err != nil {
+		return err
+	}
+	if _, err := parseCatalog(data); err != nil {
+		return err
+	}
+
+	return os.Rename(fresh, path)
+}
+
+func CatalogStale(cachePath string) bool {
+	info, err := os.Stat(CatalogPath(cachePath))
+	return err != nil || time.Since(info.ModTime()) > CatalogMaxAge
+}
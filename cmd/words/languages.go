@@ -0,0 +1,46 @@
+package words
+
+type Language struct {
+	Code            string
+	Name            string
+	CharsPerWord    int       `toml:"charsPerWord"` // Word length used to normalise WPM
+	Direction       Direction `toml:"direction"`
+	SuggestedLayout string    `toml:"layout"`
+}
+
+var Languages = []Language{
+	{Code: "en", Name: "English", CharsPerWord: 5, Direction: LeftToRight},
+	{Code: "de", Name: "German", CharsPerWord: 5, Direction: LeftToRight, SuggestedLayout: "German (US dead keys)"},
+	{Code: "lt", Name: "Lithuanian", CharsPerWord: 5, Direction: LeftToRight, SuggestedLayout: "Lithuanian"},
+	{Code: "ru", Name: "Russian", CharsPerWord: 5, Direction: LeftToRight, SuggestedLayout: "Russian (translit)"},
+	// Hebrew is written without most vowels, so its words are shorter
+	{Code: "he", Name: "Hebrew", CharsPerWord: 4, Direction: RightToLeft},
+}
+
+func LookupLanguage(code string) (Language, bool) {
+	for _, language := range Languages {
+		if language.Code == code {
+			return language, true
+		}
+	}
+
+	return Language{Code: code, Name: "Other", CharsPerWord: 5}, false
+}
+
+// Fields set in the override replace the defaults
+func (language Language) Override(override Language) Language {
+	if override.Name != "" {
+		language.Name = override.Name
+	}
+	if override.CharsPerWord > 0 {
+		language.CharsPerWord = override.CharsPerWord
+	}
+	if override.Direction != "" {
+		language.Direction = override.Direction
+	}
+	if override.SuggestedLayout != "" {
+		language.SuggestedLayout = override.SuggestedLayout
+	}
+
+	return language
+}
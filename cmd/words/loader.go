@@ -1,12 +1,22 @@
 package words
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
-	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	downloadTimeout  = 30 * time.Second
+	downloadAttempts = 3
+	downloadBackoff  = time.Second // Times the attempt number
 )
 
 func LoadWordSources(paths []string) ([]WordSource, error) {
@@ -50,27 +60,84 @@ func EnsureDir(fileName string) error {
 	return nil
 }
 
-func DownloadFile(filepath string, url string) error {
-	resp, err := http.Get(url)
+func FileChecksum(path string) (string, error) {
+	fh, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer fh.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, fh); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Errors not worth retrying, like a missing file or a client error
+type permanentError struct {
+	error
+}
+
+// Downloads into a temp file next to the destination and renames it once complete,
+// so a failed download never leaves a truncated file behind. An empty checksum skips verification.
+func DownloadFile(filePath string, url string, checksum string) error {
+	var err error
+	for attempt := 0; attempt < downloadAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(attempt) * downloadBackoff)
+		}
+
+		err = download(filePath, url, checksum)
+		if _, permanent := err.(permanentError); err == nil || permanent {
+			break
+		}
+	}
+
+	if permanent, ok := err.(permanentError); ok {
+		return permanent.error
+	}
+	return err
+}
+
+func download(filePath string, url string, checksum string) error {
+	client := http.Client{Timeout: downloadTimeout}
+	resp, err := client.Get(url)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode > 200 {
-		return errors.New("Non 200 response")
-	} else {
-		// Create the file
-		err := EnsureDir(filepath)
-		out, err := os.Create(filepath)
-		if err != nil {
-			return err
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		err := fmt.Errorf("%s: %s", url, resp.Status)
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+			return permanentError{err}
 		}
-		defer out.Close()
+		return err
+	}
 
-		// Write the body to file
-		_, err = io.Copy(out, resp.Body)
+	if err := EnsureDir(filePath); err != nil {
+		return permanentError{err}
+	}
+	out, err := os.CreateTemp(filepath.Dir(filePath), filepath.Base(filePath)+".*.part")
+	if err != nil {
+		return permanentError{err}
+	}
+	defer os.Remove(out.Name())
+
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(out, hash), resp.Body)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
 		return err
+	}
 
+	if sum := hex.EncodeToString(hash.Sum(nil)); checksum != "" && !strings.EqualFold(sum, checksum) {
+		return fmt.Errorf("%s: checksum %s, expected %s", url, sum, checksum)
 	}
+
+	return os.Rename(out.Name(), filePath)
 }
@@ -4,10 +4,21 @@ import (
 	"bufio"
 	_ "embed"
 	"encoding/json"
+	"fmt"
 	"math/rand"
 	"os"
 	"strings"
 	"time"
+
+	"golang.org/x/text/unicode/bidi"
+	"golang.org/x/text/unicode/norm"
+)
+
+type Direction string
+
+const (
+	LeftToRight Direction = "ltr"
+	RightToLeft Direction = "rtl"
 )
 
 type Metadata struct {
@@ -15,6 +26,8 @@ type Metadata struct {
 	Size       int
 	PackagedAt string //Use some kind of date type here?
 	Version    int
+	Language   string    // Language code, e.g. "en"
+	Direction  Direction // Detected from the words when not given
 }
 
 type WordSource struct {
@@ -25,9 +38,30 @@ type WordSource struct {
 //go:embed embedables/words/common-english.json
 var commonEnglish string
 
+//go:embed embedables/words/common-german.json
+var commonGerman string
+
+//go:embed embedables/words/common-lithuanian.json
+var commonLithuanian string
+
+//go:embed embedables/words/common-russian.json
+var commonRussian string
+
+//go:embed embedables/words/common-hebrew.json
+var commonHebrew string
+
 //go:embed embedables/sentences/frankenstein.json
 var frankensteinSentences string
 
+var embededSources = map[string]*string{
+	"Common words":            &commonEnglish,
+	"Common German words":     &commonGerman,
+	"Common Lithuanian words": &commonLithuanian,
+	"Common Russian words":    &commonRussian,
+	"Common Hebrew words":     &commonHebrew,
+	"Frankenstein sentences":  &frankensteinSentences,
+}
+
 func init() {
 	seed := time.Now().UnixNano()
 	rand.Seed(seed)
@@ -46,15 +80,13 @@ func check(e error) {
 }
 
 func addEmbededSource(sources map[string]WordSource) map[string]WordSource {
-	var wordSource WordSource
-	err := json.Unmarshal([]byte(commonEnglish), &wordSource)
-	check(err)
-	var sentenceSource WordSource
-	err = json.Unmarshal([]byte(frankensteinSentences), &sentenceSource)
-	check(err)
+	for name, contents := range embededSources {
+		var wordSource WordSource
+		err := json.Unmarshal([]byte(*contents), &wordSource)
+		check(err)
 
-	sources["Common words"] = wordSource
-	sources["Frankenstein sentences"] = sentenceSource
+		sources[name] = wordSource
+	}
 
 	return sources
 }
@@ -63,42 +95,54 @@ func unmarshalSources(paths []string) map[string]WordSource {
 	acc := make(map[string]WordSource, len(paths))
 	for _, sourceFilePath := range paths {
 		var wordSource WordSource
+		var err error
 		if strings.HasSuffix(sourceFilePath, ".json") {
-			wordSource = readJsonSource(sourceFilePath)
+			wordSource, err = readJsonSource(sourceFilePath)
 		} else {
-			wordSource = readNewLineSource(sourceFilePath)
+			wordSource, err = readNewLineSource(sourceFilePath)
 		}
 
-		acc[sourceFilePath] = wordSource
+		// Unreadable sources are left out, their lists simply have no words
+		if err == nil {
+			acc[sourceFilePath] = wordSource
+		}
 	}
 
 	return acc
 }
 
-func readJsonSource(sourceFilePath string) WordSource {
+func readJsonSource(sourceFilePath string) (WordSource, error) {
 	var wordSource WordSource
 
 	fh, err := os.Open(sourceFilePath)
+	if err != nil {
+		return wordSource, err
+	}
 	defer fh.Close()
-	check(err)
 
 	decoder := json.NewDecoder(fh)
-	err = decoder.Decode(&wordSource)
-	check(err)
+	if err := decoder.Decode(&wordSource); err != nil {
+		return WordSource{}, fmt.Errorf("%s: %w", sourceFilePath, err)
+	}
 
-	return wordSource
+	return wordSource, nil
 }
 
-func readNewLineSource(sourceFilePath string) WordSource {
+func readNewLineSource(sourceFilePath string) (WordSource, error) {
 	fh, err := os.Open(sourceFilePath)
+	if err != nil {
+		return WordSource{}, err
+	}
 	defer fh.Close()
-	check(err)
 
 	var lines []string
 	scanner := bufio.NewScanner(fh)
 	for scanner.Scan() {
 		lines = append(lines, scanner.Text())
 	}
+	if err := scanner.Err(); err != nil {
+		return WordSource{}, err
+	}
 
 	metadata := Metadata{
 		Name:       fh.Name(),
@@ -110,7 +154,7 @@ func readNewLineSource(sourceFilePath string) WordSource {
 	return WordSource{
 		Metadata: metadata,
 		Words:    lines,
-	}
+	}, nil
 }
 
 func NewGenerator(paths []string) (g WordsGenerator) {
@@ -121,6 +165,59 @@ func NewGenerator(paths []string) (g WordsGenerator) {
 	return g
 }
 
+func (this WordsGenerator) Language(listName string) string {
+	return this.poolsJson[listName].Metadata.Language
+}
+
+// Direction given in the list itself wins over the fallback, detection is the last resort
+func (this WordsGenerator) Direction(listName string, fallback Direction) Direction {
+	source := this.poolsJson[listName]
+	if source.Metadata.Direction != "" {
+		return source.Metadata.Direction
+	}
+	if fallback != "" {
+		return fallback
+	}
+
+	return DetectDirection(source.Words)
+}
+
+// Direction of the majority of strongly directional letters
+func DetectDirection(words []string) Direction {
+	var ltr, rtl int
+	for _, word := range words {
+		for _, letter := range word {
+			props, _ := bidi.LookupRune(letter)
+			switch props.Class() {
+			case bidi.L:
+				ltr++
+			case bidi.R, bidi.AL:
+				rtl++
+			}
+		}
+	}
+
+	if rtl > ltr {
+		return RightToLeft
+	}
+	return LeftToRight
+}
+
+// All words of the list in their original order
+func (this WordsGenerator) Words(listName string) ([]string, bool) {
+	source, ok := this.poolsJson[listName]
+	if !ok {
+		return nil, false
+	}
+
+	acc := make([]string, len(source.Words))
+	for idx, word := range source.Words {
+		acc[idx] = norm.NFC.String(word)
+	}
+
+	return acc, true
+}
+
 func (this WordsGenerator) Generate(listName string) []rune {
 	pool := this.poolsJson[listName].Words
 
@@ -130,5 +227,6 @@ func (this WordsGenerator) Generate(listName string) []rune {
 	takeAmount := min(this.Count, len(pool))
 	words := pool[0:takeAmount]
 
-	return []rune(strings.Join(words, " "))
+	// Lists might carry decomposed letters, while terminals send composed ones
+	return []rune(norm.NFC.String(strings.Join(words, " ")))
 }
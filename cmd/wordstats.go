@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+const practiceWordList = "practice"
+
+// How many of the worst words are shown and practiced
+const problemWordsLimit = 5
+
+// Times each problem word is repeated in a practice test
+const practiceRepeats = 3
+
+type WordStat struct {
+	expected    string
+	typed       string        // Final input, mistakes corrected later are not visible here
+	perLetter   time.Duration // Including the space leading to the word
+	mistyped    bool          // Mistakes at the end, or corrected during the test
+	uncorrected bool
+}
+
+// Fully typed words of the test, in order
+func (base TestBase) wordStats() []WordStat {
+	var acc []WordStat
+
+	for start := 0; start < len(base.wordsToEnter); {
+		end := start
+		for end < len(base.wordsToEnter) && base.wordsToEnter[end] != ' ' {
+			end++
+		}
+		if end > len(base.inputBuffer) {
+			break
+		}
+
+		if end > start {
+			acc = append(acc, base.wordStat(start, end))
+		}
+		start = end + 1
+	}
+
+	return acc
+}
+
+func (base TestBase) wordStat(start int, end int) WordStat {
+	stat := WordStat{
+		expected: string(base.wordsToEnter[start:end]),
+		typed:    string(base.typedRange(start, end)),
+	}
+
+	for at := start; at < end; at++ {
+		if base.mistakes.mistakesAt[at] {
+			stat.mistyped = true
+			stat.uncorrected = true
+		}
+	}
+	if len(base.overflow[end]) > 0 {
+		stat.mistyped = true
+		stat.uncorrected = true
+	}
+
+	first, last := -1, -1
+	for idx, event := range base.events {
+		if event.Kind != runeEvent || event.Cursor < start || event.Cursor >= end {
+			continue
+		}
+		if first < 0 {
+			first = idx
+		}
+		last = idx
+		if event.Mapped != event.Expected {
+			stat.mistyped = true
+		}
+	}
+
+	if first >= 0 {
+		letters := end - start
+		from := base.events[first].At
+		if first > 0 {
+			from = base.events[first-1].At
+		} else {
+			letters--
+		}
+		if letters > 0 {
+			stat.perLetter = (base.events[last].At - from) / time.Duration(letters)
+		}
+	}
+
+	return stat
+}
+
+func slowestWords(stats []WordStat) []WordStat {
+	sorted := append([]WordStat{}, stats...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].perLetter > sorted[j].perLetter })
+
+	return distinctWords(sorted, problemWordsLimit)
+}
+
+func mistypedWords(stats []WordStat) []WordStat {
+	var acc []WordStat
+	for _, stat := range stats {
+		if stat.mistyped {
+			acc = append(acc, stat)
+		}
+	}
+
+	return distinctWords(acc, problemWordsLimit)
+}
+
+func distinctWords(stats []WordStat, limit int) []WordStat {
+	var acc []WordStat
+	seen := map[string]bool{}
+	for _, stat := range stats {
+		if len(acc) == limit {
+			break
+		}
+		if !seen[stat.expected] {
+			seen[stat.expected] = true
+			acc = append(acc, stat)
+		}
+	}
+
+	return acc
+}
+
+// Mistyped words first, then the slowest ones
+func practiceWords(stats []WordStat) []string {
+	var acc []string
+	seen := map[string]bool{}
+	for _, stat := range append(mistypedWords(stats), slowestWords(stats)...) {
+		if !seen[stat.expected] {
+			seen[stat.expected] = true
+			acc = append(acc, stat.expected)
+		}
+	}
+
+	return acc
+}
+
+func practiceText(words []string) []rune {
+	var acc []string
+	for _, word := range words {
+		for i := 0; i < practiceRepeats; i++ {
+			acc = append(acc, word)
+		}
+	}
+	rand.Shuffle(len(acc), func(i, j int) { acc[i], acc[j] = acc[j], acc[i] })
+
+	return []rune(strings.Join(acc, " "))
+}
+
+func (m model) wordsPanelView(panel ResultsPanel) string {
+	slowest := []string{style("slowest", m.styles.greener)}
+	for _, stat := range slowestWords(panel.words) {
+		slowest = append(slowest, fmt.Sprintf("%s %s", stat.expected, style(fmt.Sprintf("%dms/letter", stat.perLetter.Milliseconds()), m.styles.toEnter)))
+	}
+
+	mistyped := []string{style("mistyped", m.styles.greener)}
+	for _, stat := range mistypedWords(panel.words) {
+		if stat.uncorrected {
+			mistyped = append(mistyped, fmt.Sprintf("%s %s", stat.expected, style(stat.typed, m.styles.mistakes)))
+		} else {
+			mistyped = append(mistyped, fmt.Sprintf("%s %s", stat.expected, style("corrected", m.styles.toEnter)))
+		}
+	}
+
+	columns := lipgloss.JoinHorizontal(lipgloss.Top,
+		lipgloss.NewStyle().PaddingRight(4).Render(strings.Join(slowest, "\n")),
+		strings.Join(mistyped, "\n"),
+	)
+	caption := style(fmt.Sprintf("words (%s: switch view, %s: practice these words)", m.keymap.key(switchViewAction), m.keymap.key(practiceAction)), m.styles.toEnter)
+
+	return "\n" + columns + "\n\n" + caption + "\n"
+}
@@ -1,7 +1,7 @@
 package main
 
 import (
-	"log"
+	"os"
 
 	"github.com/bloznelis/typioca/cmd"
 )
@@ -9,7 +9,8 @@ import (
 func main() {
 	cmd.OsInit()
 
+	// Cobra already printed the error
 	if err := cmd.RootCmd.Execute(); err != nil {
-		log.Fatal(err)
+		os.Exit(1)
 	}
 }